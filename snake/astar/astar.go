@@ -0,0 +1,135 @@
+// Package astar is a generic A* pathfinding implementation. It searches any
+// graph whose nodes are comparable values, like coordinates, ids or pointers,
+// described by neighbor, cost and heuristic functions.
+package astar
+
+import "container/heap"
+
+// Graph describes the graph Path searches.
+type Graph[N comparable] struct {
+	// Neighbors appends the nodes that can be reached in one step from a node
+	// to buf and returns it.
+	Neighbors func(n N, buf []N) []N
+	// Cost returns the exact cost of the step between two neighbors.
+	Cost func(from, to N) float64
+	// Heuristic estimates the cost from a node to the goal. It must not
+	// overestimate for the path to be the cheapest. A nil Heuristic searches
+	// like Dijkstra.
+	Heuristic func(n, goal N) float64
+	// MaxExpansions stops a search after it expanded that many nodes without
+	// reaching the goal, 0 means no limit.
+	MaxExpansions int
+	// Expanded is called with every node the search expands, if it is set.
+	Expanded func(n N)
+	// Reached is called with every node the search queues, if it is set. A
+	// node is queued again when it is reached cheaper.
+	Reached func(n N)
+}
+
+// node holds the A* data of a graph node.
+type node[N comparable] struct {
+	n      N
+	cost   float64
+	rank   float64
+	parent int
+	closed bool
+}
+
+// Path calculates the cheapest path between two nodes and its cost. The path
+// is in forward order, from first and to last. The search stops as soon as
+// it expands the goal.
+//
+// If no path is found, or the expansion limit is reached first, found will be
+// false.
+func Path[N comparable](g Graph[N], from, to N) (path []N, cost float64, found bool) {
+	s := &search[N]{graph: g, goal: to, index: map[N]int{}}
+	s.reach(s.get(from), -1, 0)
+
+	var buf []N
+	expansions := 0
+	for s.open.Len() > 0 {
+		e := heap.Pop(&s.open).(entry)
+		current := &s.nodes[e.node]
+		if current.closed || e.rank > current.rank {
+			// a stale entry of a node that was reached cheaper later
+			continue
+		}
+		current.closed = true
+		if g.Expanded != nil {
+			g.Expanded(current.n)
+		}
+		if current.n == to {
+			return s.reconstruct(e.node), current.cost, true
+		}
+		expansions++
+		if g.MaxExpansions > 0 && expansions >= g.MaxExpansions {
+			return nil, 0, false
+		}
+
+		from, fromCost := current.n, current.cost
+		buf = g.Neighbors(from, buf[:0])
+		for _, neighbor := range buf {
+			i, seen := s.index[neighbor]
+			if !seen {
+				i = s.get(neighbor)
+			}
+			n := &s.nodes[i]
+			cost := fromCost + g.Cost(from, neighbor)
+			if n.closed || seen && cost >= n.cost {
+				continue
+			}
+			s.reach(i, e.node, cost)
+		}
+	}
+	return nil, 0, false
+}
+
+// search is the state of one Path call.
+type search[N comparable] struct {
+	graph Graph[N]
+	goal  N
+	nodes []node[N]
+	index map[N]int
+	open  priorityQueue
+}
+
+// get returns the index of the node of a graph node, adding it if required.
+func (s *search[N]) get(n N) int {
+	i, ok := s.index[n]
+	if !ok {
+		i = len(s.nodes)
+		s.nodes = append(s.nodes, node[N]{n: n, parent: -1})
+		s.index[n] = i
+	}
+	return i
+}
+
+// reach records a cheaper way to a node and queues it.
+func (s *search[N]) reach(i, parent int, cost float64) {
+	n := &s.nodes[i]
+	h := 0.0
+	if s.graph.Heuristic != nil {
+		h = s.graph.Heuristic(n.n, s.goal)
+	}
+	n.cost = cost
+	n.rank = cost + h
+	n.parent = parent
+	heap.Push(&s.open, entry{node: i, rank: n.rank, h: h})
+	if s.graph.Reached != nil {
+		s.graph.Reached(n.n)
+	}
+}
+
+// reconstruct follows the parents of the goal back to the start.
+func (s *search[N]) reconstruct(goal int) []N {
+	count := 0
+	for i := goal; i != -1; i = s.nodes[i].parent {
+		count++
+	}
+	path := make([]N, count)
+	for i := goal; i != -1; i = s.nodes[i].parent {
+		count--
+		path[count] = s.nodes[i].n
+	}
+	return path
+}
@@ -0,0 +1,95 @@
+package astar
+
+import (
+	"reflect"
+	"testing"
+)
+
+// edges are the costs of the steps of a small directed graph, f is cut off.
+var edges = map[string]map[string]float64{
+	"a": {"b": 1, "c": 1},
+	"b": {"d": 4},
+	"c": {"d": 1, "b": 1},
+	"d": {"e": 1},
+	"e": {},
+	"f": {"a": 1},
+}
+
+func testGraph() Graph[string] {
+	return Graph[string]{
+		Neighbors: func(n string, buf []string) []string {
+			for _, to := range []string{"a", "b", "c", "d", "e", "f"} {
+				if _, ok := edges[n][to]; ok {
+					buf = append(buf, to)
+				}
+			}
+			return buf
+		},
+		Cost: func(from, to string) float64 { return edges[from][to] },
+	}
+}
+
+func TestPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		limit    int
+		path     []string
+		cost     float64
+		found    bool
+	}{
+		{"cheapest", "a", "e", 0, []string{"a", "c", "d", "e"}, 3, true},
+		{"start is the goal", "a", "a", 0, []string{"a"}, 0, true},
+		{"one step", "c", "b", 0, []string{"c", "b"}, 1, true},
+		{"unreachable", "a", "f", 0, nil, 0, false},
+		{"dead end", "e", "a", 0, nil, 0, false},
+		{"within the limit", "a", "e", 5, []string{"a", "c", "d", "e"}, 3, true},
+		{"over the limit", "a", "e", 2, nil, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := testGraph()
+			g.MaxExpansions = tt.limit
+			path, cost, found := Path(g, tt.from, tt.to)
+			if found != tt.found || cost != tt.cost || !reflect.DeepEqual(path, tt.path) {
+				t.Errorf("Path = %v %v %v, want %v %v %v", path, cost, found, tt.path, tt.cost, tt.found)
+			}
+		})
+	}
+}
+
+func TestPathHeuristic(t *testing.T) {
+	// the heuristic of the line of cells 0 to 9 is exact, so the search only
+	// expands the cells on the way to the goal
+	g := Graph[int]{
+		Neighbors: func(n int, buf []int) []int {
+			if n > 0 {
+				buf = append(buf, n-1)
+			}
+			if n < 9 {
+				buf = append(buf, n+1)
+			}
+			return buf
+		},
+		Cost: func(from, to int) float64 { return 1 },
+		Heuristic: func(n, goal int) float64 {
+			if n > goal {
+				return float64(n - goal)
+			}
+			return float64(goal - n)
+		},
+	}
+	var expanded, reached []int
+	g.Expanded = func(n int) { expanded = append(expanded, n) }
+	g.Reached = func(n int) { reached = append(reached, n) }
+	path, cost, found := Path(g, 5, 8)
+	if !found || cost != 3 || !reflect.DeepEqual(path, []int{5, 6, 7, 8}) {
+		t.Fatalf("Path = %v %v %v, want [5 6 7 8] 3 true", path, cost, found)
+	}
+	if want := []int{5, 6, 7, 8}; !reflect.DeepEqual(expanded, want) {
+		t.Errorf("expanded %v, want %v", expanded, want)
+	}
+	if want := []int{5, 4, 6, 7, 8}; !reflect.DeepEqual(reached, want) {
+		t.Errorf("reached %v, want %v", reached, want)
+	}
+}
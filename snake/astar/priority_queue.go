@@ -0,0 +1,40 @@
+package astar
+
+// entry is a node queued with the rank it had when it was queued.
+type entry struct {
+	node int
+	rank float64
+	h    float64
+}
+
+// A priorityQueue implements heap.Interface and holds entries. The
+// priorityQueue is used to track open nodes by rank, preferring the ones
+// closer to the goal on equal rank.
+type priorityQueue []entry
+
+func (pq priorityQueue) Len() int {
+	return len(pq)
+}
+
+func (pq priorityQueue) Less(i, j int) bool {
+	if pq[i].rank != pq[j].rank {
+		return pq[i].rank < pq[j].rank
+	}
+	return pq[i].h < pq[j].h
+}
+
+func (pq priorityQueue) Swap(i, j int) {
+	pq[i], pq[j] = pq[j], pq[i]
+}
+
+func (pq *priorityQueue) Push(x interface{}) {
+	*pq = append(*pq, x.(entry))
+}
+
+func (pq *priorityQueue) Pop() interface{} {
+	old := *pq
+	n := len(old)
+	e := old[n-1]
+	*pq = old[0 : n-1]
+	return e
+}
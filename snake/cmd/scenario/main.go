@@ -0,0 +1,39 @@
+// Command scenario plays scenario files and reports the expectations that
+// failed. It exits with status 1 if any scenario failed.
+//
+//	go run ./cmd/scenario scenarios/*.txt
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"snake2/snake"
+)
+
+func main() {
+	failed := false
+	for _, path := range os.Args[1:] {
+		sc, err := snake.LoadScenario(path)
+		if err == nil {
+			var failures []string
+			if _, failures, err = sc.Run(); err == nil && len(failures) > 0 {
+				failed = true
+				fmt.Printf("FAIL %v\n", path)
+				for _, f := range failures {
+					fmt.Printf("     %v\n", f)
+				}
+				continue
+			}
+		}
+		if err != nil {
+			failed = true
+			fmt.Printf("ERR  %v: %v\n", path, err)
+			continue
+		}
+		fmt.Printf("ok   %v\n", path)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
@@ -2,9 +2,10 @@ module snake2
 
 go 1.19
 
+require github.com/gdamore/tcell v1.4.0
+
 require (
 	github.com/gdamore/encoding v1.0.0 // indirect
-	github.com/gdamore/tcell v1.4.0 // indirect
 	github.com/lucasb-eyer/go-colorful v1.0.3 // indirect
 	github.com/mattn/go-runewidth v0.0.7 // indirect
 	golang.org/x/sys v0.0.0-20190626150813-e07cf5db2756 // indirect
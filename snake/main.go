@@ -1,10 +1,13 @@
 package main
 
 import (
+	"log"
 	"snake2/snake"
 )
 
 func main() {
 	//use only 1 food and 1 snake currently!
-	snake.StartGame(1, 1, 1)
+	if err := snake.StartGame(1, 1, 1); err != nil {
+		log.Fatal(err)
+	}
 }
@@ -1,11 +1,9 @@
 package snake
 
 import (
-	"container/heap"
+	"snake2/astar"
 )
 
-// astar is an A* pathfinding implementation.
-
 // Pather is an interface which allows A* searching on arbitrary objects which
 // can represent a weighted graph.
 type Pather interface {
@@ -19,96 +17,53 @@ type Pather interface {
 	PathEstimatedCost(to Pather) float64
 }
 
-// node is a wrapper to store A* data for a Pather node.
-type node struct {
-	pather Pather
-	cost   float64
-	rank   float64
-	parent *node
-	open   bool
-	closed bool
-	index  int
-}
-
-// nodeMap is a collection of nodes keyed by Pather nodes for quick reference.
-type nodeMap map[Pather]*node
-
-// get gets the Pather object wrapped in a node, instantiating if required.
-func (nm nodeMap) get(p Pather) *node {
-	n, ok := nm[p]
-	if !ok {
-		n = &node{
-			pather: p,
-		}
-		nm[p] = n
-	}
-	return n
-}
-
-// Path calculates a short path and the distance between the two Pather nodes.
+// Path calculates a short path and the distance between the two Pather nodes
+// with the astar package. The path is in forward order, from first.
 //
 // If no path is found, found will be false.
-func Path(from, to Pather, g *Game) (path []Pather, distance float64, found bool) {
-	nm := nodeMap{}
-	nq := &priorityQueue{}
-	heap.Init(nq)
-	fromNode := nm.get(from)
-	fromNode.open = true
-	heap.Push(nq, fromNode)
-
-	if to == nil {
+func Path(from, to Pather) (path []Pather, distance float64, found bool) {
+	if missing(from) || missing(to) {
 		return
 	}
-
-	for {
-		if nq.Len() == 0 {
-			// There's no path, return found false.
-			return
+	// Pathers are searched by id, as interfaces are no comparable type
+	// parameters.
+	ids := map[Pather]int{}
+	pathers := make([]Pather, 0)
+	id := func(p Pather) int {
+		i, ok := ids[p]
+		if !ok {
+			i = len(pathers)
+			ids[p] = i
+			pathers = append(pathers, p)
 		}
-		current := heap.Pop(nq).(*node)
-		current.open = false
-		current.closed = true
-
-		if current == nm.get(to) {
-			// Found a path to the goal.
-			p := []Pather{}
-			curr := current
-			for curr != nil {
-				p = append(p, curr.pather)
-				curr = curr.parent
-			}
-			return p, current.cost, true
-		}
-
-		for _, neighbor := range current.pather.PathNeighbors() {
-			cost := current.cost + current.pather.PathNeighborCost(neighbor)
-			neighborNode := nm.get(neighbor)
-			if cost < neighborNode.cost {
-				if neighborNode.open {
-					heap.Remove(nq, neighborNode.index)
-				}
-				neighborNode.open = false
-				neighborNode.closed = false
+		return i
+	}
+	graph := astar.Graph[int]{
+		Neighbors: func(n int, buf []int) []int {
+			for _, p := range pathers[n].PathNeighbors() {
+				buf = append(buf, id(p))
 			}
-			if !neighborNode.open && !neighborNode.closed {
-				neighborNode.cost = cost
-				neighborNode.open = true
+			return buf
+		},
+		Cost: func(from, to int) float64 {
+			return pathers[from].PathNeighborCost(pathers[to])
+		},
+		Heuristic: func(n, goal int) float64 {
+			return pathers[n].PathEstimatedCost(pathers[goal])
+		},
+	}
+	p, distance, found := astar.Path(graph, id(from), id(to))
+	for _, n := range p {
+		path = append(path, pathers[n])
+	}
+	return path, distance, found
+}
 
-				// func() {
-				// 	defer func() {
-				// 		if r := recover(); r != nil {
-				// 			// g.TestFieldError = fmt.Sprintf("Error: %+v, nei: %v %v,", r, neighbor.(*Tile).X, neighbor.(*Tile).Y)
-				// 			// g.TestFieldError = fmt.Sprintf("Error: %+v, to: %v,", r, to)
-				// 			// g.Pause()
-				// 			g.TestField3 = fmt.Sprintf("err: %v, to: %v", r, to)
-				// 			g.TestBool = true
-				// 		}
-				// 	}()
-				neighborNode.rank = cost + neighbor.PathEstimatedCost(to)
-				// }()
-				neighborNode.parent = current
-				heap.Push(nq, neighborNode)
-			}
-		}
+// missing tells if there is no pather. A nil *Tile, as World.Tile and World.To
+// return for no tile, is no nil Pather, so the tile pointer is checked too.
+func missing(p Pather) bool {
+	if t, ok := p.(*Tile); ok {
+		return t == nil
 	}
+	return p == nil
 }
@@ -3,6 +3,9 @@ package snake
 type Board struct {
 	width, height int
 	area          []Coordinate
+	// terrain holds the tile kind of every cell row by row, indexed like a
+	// Grid of the board. It is nil while the board is plain.
+	terrain []int
 }
 
 // Create a new board.
@@ -14,5 +17,5 @@ func newBoard(width int, height int) *Board {
 		}
 	}
 
-	return &Board{width, height, area}
+	return &Board{width: width, height: height, area: area}
 }
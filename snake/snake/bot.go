@@ -1,5 +1,7 @@
 package snake
 
+import "time"
+
 // type Tree struct {
 // 	root *Node
 // }
@@ -69,3 +71,173 @@ package snake
 // // 		return -5
 // // 	}
 // // }
+
+// A Bot decides the direction of a bot snake every tick.
+type Bot interface {
+	// NextDirection returns the direction the snake should take next, or a
+	// negative value if it should keep its direction.
+	NextDirection(g *Game, snakeNumber int) int
+}
+
+// Bot types of the bot settings.
+const (
+	BotClassic  = "classic"
+	BotSurvival = "survival"
+	BotHamilton = "hamilton"
+	BotOpponent = "opponent"
+	BotSearch   = "search"
+)
+
+// newBot creates the bot of the i-th bot snake from the bot settings.
+func (g *Game) newBot(i int) Bot {
+	setting := BotSetting{Type: BotClassic}
+	if i < len(g.settings.BotSettings) {
+		setting = g.settings.BotSettings[i]
+	}
+	level := difficultyOf(setting.Difficulty)
+	style := g.personalityOf(setting.Personality)
+	targets := g.newFoodTargets(setting.Target)
+	seed := g.botSeed(i)
+	var bot Bot
+	switch setting.Type {
+	case BotSurvival:
+		bot = newSurvivalBot(g.Board, level, g.newGridSearch(setting.Search, SearchDStar), style, targets)
+	case BotHamilton:
+		bot = newHamiltonBot(g.Board, targets)
+	case BotOpponent:
+		bot = newOpponentBot(g.Board, level, g.newGridSearch(setting.Search, SearchDStar), style, targets)
+	case BotSearch:
+		bot = newSearchBot(g.Board, time.Duration(setting.BudgetMs)*time.Millisecond, level, seed)
+	default:
+		bot = newClassicBot(g.Board, g.newGridSearch(setting.Search, SearchGeneric), targets)
+	}
+	if setting.Team != "" && !g.teamPlanner(setting.Team).join(g.PlayerNumber+i, bot) {
+		g.logger.Warn("bot can't play in a team", "type", setting.Type, "team", setting.Team)
+	}
+	return withDifficulty(bot, level, seed)
+}
+
+// botSeed is the seed of the randomness of the i-th bot. It comes from the seed
+// of the game, so a scenario plays the same with the same seed, but every bot
+// gets its own.
+func (g *Game) botSeed(i int) int64 {
+	return g.seed + int64(g.PlayerNumber+i)
+}
+
+// classicBot goes straight for the food with A*. Without a path it waits for
+// the bodies in its way to move with a space-time search, and turns greedily
+// when that finds none either.
+type classicBot struct {
+	teamwork
+	grid    *Grid
+	search  GridSearch
+	timed   *spaceTimeSearch
+	targets *foodTargets
+}
+
+func newClassicBot(board *Board, search GridSearch, targets *foodTargets) *classicBot {
+	return &classicBot{grid: newBoardGrid(board), search: search, timed: newSpaceTimeSearch(), targets: targets}
+}
+
+func (b *classicBot) joinTeam(p *teamPlanner) *foodTargets {
+	b.join(p, b.targets, b.timed)
+	return b.targets
+}
+
+func (b *classicBot) NextDirection(g *Game, snakeNumber int) int {
+	botSnake := g.Snakes[snakeNumber]
+	g.fillGrid(b.grid, botSnake)
+	b.keepOut(g, snakeNumber, b.grid)
+
+	headCordinate := botSnake.SnakeParts[0].Coordinate
+	foodCordinate, hasFood := b.targets.choose(g, snakeNumber, b.grid)
+	if !hasFood {
+		// the board is full, go straight while it can
+		foodCordinate = headCordinate.step(botSnake.Direction)
+	}
+
+	g.logger.Debug("bot plan", "snake", snakeNumber+1, "head", headCordinate, "food", foodCordinate)
+
+	p, _, found := b.search.Path(b.grid, headCordinate, foodCordinate)
+	g.setBotSearch(snakeNumber, b.search)
+	if !found {
+		p, _, _ = b.timed.Path(b.grid, headCordinate, foodCordinate)
+		g.setBotSearch(snakeNumber, b.timed)
+	}
+	g.setBotPath(snakeNumber, p)
+	if len(p) >= 2 {
+		return turnTowards(headCordinate, p[1], botSnake, b.grid)
+	}
+	return turnTowards(headCordinate, foodCordinate, botSnake, b.grid)
+}
+
+// turnTowards returns the direction a snake takes towards a goal, the first
+// move it can make on the grid of its bot. It is -1, the snake keeps its
+// direction, if there is no move left to try.
+func turnTowards(currentHeadPosition Coordinate, goalCoordinate Coordinate, snake *Snake, gr *Grid) int {
+	var difference Coordinate
+	difference.x = currentHeadPosition.x - goalCoordinate.x
+	difference.y = currentHeadPosition.y - goalCoordinate.y
+
+	var dir int = 0
+	var right bool = false
+	var left bool = false
+	var up bool = false
+	var down bool = false
+	for i := 0; i < 4; i++ {
+		//2
+		if (difference.x < 0 || left) && !right {
+			dir = Right
+			right = true
+			//1
+		} else if (difference.x > 0 || right) && !left {
+			dir = Left
+			left = true
+			//3
+		} else if (difference.y < 0 || up) && !down {
+			dir = Down
+			down = true
+			//0
+		} else if (difference.y > 0 || down) && !up {
+			dir = Up
+			up = true
+		} else {
+			return -1
+		}
+		cm := snake.canMoveBot(gr, dir)
+		if cm {
+			break
+		}
+	}
+	return dir
+}
+
+// snakeBody returns a copy of the coordinates and the direction of a snake.
+func (g *Game) snakeBody(snakeNumber int) ([]Coordinate, int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	s := g.Snakes[snakeNumber]
+	return s.body(), s.Direction
+}
+
+// setBotPath stores the planned path of a bot for display.
+func (g *Game) setBotPath(snakeNumber int, path []Coordinate) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.BotPaths[snakeNumber] = append([]Coordinate{}, path...)
+}
+
+// snakeBodies returns a copy of the coordinates of every snake as seen by the
+// bot of a snake, the bodies of snakes hidden from it are nil.
+func (g *Game) snakeBodies(viewer int) [][]Coordinate {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	bodies := make([][]Coordinate, len(g.Snakes))
+	for i, s := range g.Snakes {
+		if i != viewer && g.hidden(s) {
+			continue
+		}
+		bodies[i] = s.body()
+	}
+	return bodies
+}
@@ -0,0 +1,166 @@
+package snake
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// botPool runs the decisions of the bots on a bounded number of workers, as
+// many as the Go scheduler runs in parallel. A bot never decides twice at the
+// same time: while it is busy it gets no new job. A bot that is still busy
+// after its deadline holds its worker, so the pool starts a spare one for it
+// and lets it go when the bot is done.
+type botPool struct {
+	g       *Game
+	slots   []*botSlot
+	jobs    chan botJob
+	workers int
+	running atomic.Int32
+	ctx     context.Context
+	run     func(func())
+}
+
+// botSlot is a bot of the pool and the snake it controls. reset tells the
+// bot to forget the last game before its next decision.
+type botSlot struct {
+	bot         Bot
+	snakeNumber int
+	busy        atomic.Bool
+	reset       atomic.Bool
+}
+
+// resetter is a bot that keeps state from one tick to the next which a new
+// game must not see.
+type resetter interface {
+	reset()
+}
+
+// botJob asks a bot for its move in a tick. The bot decides on view, and a
+// job still waiting at its deadline is dropped. done gets the decision.
+type botJob struct {
+	slot     *botSlot
+	view     *Game
+	tick     int
+	deadline time.Time
+	done     func(BotDecision)
+}
+
+// newBotPool creates the pool of the bots of a game, the i-th bot controls the
+// i-th snake after the players.
+func newBotPool(g *Game, bots []Bot) *botPool {
+	p := &botPool{
+		g:       g,
+		slots:   make([]*botSlot, len(bots)),
+		jobs:    make(chan botJob, len(bots)),
+		workers: runtime.GOMAXPROCS(0),
+	}
+	for i, bot := range bots {
+		p.slots[i] = &botSlot{bot: bot, snakeNumber: g.PlayerNumber + i}
+	}
+	if p.workers > len(bots) {
+		p.workers = len(bots)
+	}
+	return p
+}
+
+// start runs the workers of the pool with run until ctx is done.
+func (p *botPool) start(ctx context.Context, run func(func())) {
+	p.ctx, p.run = ctx, run
+	p.grow()
+}
+
+// grow starts workers until there are enough of them besides the ones held by
+// busy bots.
+func (p *botPool) grow() {
+	for int(p.running.Load()) < p.workers+p.busy() {
+		p.running.Add(1)
+		p.run(p.work)
+	}
+}
+
+// busy returns the number of bots that are deciding or waiting for a worker.
+func (p *botPool) busy() int {
+	n := 0
+	for _, slot := range p.slots {
+		if slot.busy.Load() {
+			n++
+		}
+	}
+	return n
+}
+
+// reset makes every bot forget the last game. A bot may still be deciding on
+// it, so the bot is reset by the worker of its next job.
+func (p *botPool) reset() {
+	for _, slot := range p.slots {
+		slot.reset.Store(true)
+	}
+}
+
+// submit queues a job, it tells if the bot was free to take it.
+func (p *botPool) submit(job botJob) bool {
+	if !job.slot.busy.CompareAndSwap(false, true) {
+		return false
+	}
+	// at most one job per bot is queued, so this never blocks
+	p.jobs <- job
+	return true
+}
+
+// work runs jobs until ctx of the pool is done, or until the pool has more
+// workers than it needs.
+func (p *botPool) work() {
+	defer p.running.Add(-1)
+	for {
+		var job botJob
+		select {
+		case <-p.ctx.Done():
+			return
+		case job = <-p.jobs:
+		}
+		if !job.deadline.IsZero() && time.Now().After(job.deadline) {
+			// the engine counts it as a timeout
+			job.slot.busy.Store(false)
+			continue
+		}
+		if r, ok := job.slot.bot.(resetter); ok && job.slot.reset.Swap(false) {
+			r.reset()
+		}
+		startTime := time.Now()
+		nextstep := job.slot.bot.NextDirection(job.view, job.slot.snakeNumber)
+		p.g.recordPlanning(job.slot.snakeNumber, time.Since(startTime))
+		d := job.view.decision(job.slot.snakeNumber, job.tick, nextstep)
+		job.slot.busy.Store(false)
+		job.done(d)
+		if int(p.running.Load()) > p.workers+p.busy() {
+			// a spare worker of a bot that was late
+			return
+		}
+	}
+}
+
+// decideAll lets every bot decide on a view without a deadline and waits for
+// them. The decisions are in the order of the bots, whatever order the
+// workers finish in.
+func (p *botPool) decideAll(view *Game, tick int) []BotDecision {
+	p.grow()
+	decisions := make([]BotDecision, len(p.slots))
+	var wg sync.WaitGroup
+	for i, slot := range p.slots {
+		i := i
+		decisions[i] = BotDecision{SnakeID: slot.snakeNumber, Tick: tick, Dir: -1}
+		wg.Add(1)
+		job := botJob{slot: slot, view: view, tick: tick, done: func(d BotDecision) {
+			decisions[i] = d
+			wg.Done()
+		}}
+		if !p.submit(job) {
+			wg.Done()
+		}
+	}
+	wg.Wait()
+	return decisions
+}
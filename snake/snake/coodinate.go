@@ -8,3 +8,29 @@ type Coordinate struct {
 func newCoordinate(x, y int) Coordinate {
 	return Coordinate{x, y}
 }
+
+// Get the neighboring coordinate in a direction.
+func (c Coordinate) step(dir int) Coordinate {
+	switch dir {
+	case Up:
+		return newCoordinate(c.x, c.y-1)
+	case Left:
+		return newCoordinate(c.x-1, c.y)
+	case Right:
+		return newCoordinate(c.x+1, c.y)
+	case Down:
+		return newCoordinate(c.x, c.y+1)
+	}
+	return c
+}
+
+// Get the direction that leads to a neighboring coordinate, -1 if the
+// coordinates are not neighbors.
+func (c Coordinate) directionTo(to Coordinate) int {
+	for _, dir := range []int{Up, Left, Right, Down} {
+		if c.step(dir) == to {
+			return dir
+		}
+	}
+	return -1
+}
@@ -0,0 +1,80 @@
+package snake
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gdamore/tcell"
+)
+
+// debugEventLimit is the number of recent events the debug panel keeps.
+const debugEventLimit = 8
+
+var directionNames = map[int]string{
+	Up:    "up",
+	Left:  "left",
+	Right: "right",
+	Down:  "down",
+}
+
+// event logs something that happened in the game and keeps it for the debug
+// panel. Must not hold g.mu.
+func (g *Game) event(msg string, keyValues ...interface{}) {
+	g.logger.Info(msg, keyValues...)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.events = append(g.events, msg+formatKeyValues(keyValues))
+	if len(g.events) > debugEventLimit {
+		g.events = g.events[len(g.events)-debugEventLimit:]
+	}
+}
+
+func (g *Game) toggleDebug() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.ShowDebug = !g.ShowDebug
+}
+
+// Display the debug panel under the board: the tick, the state and bot
+// timings of every snake and the recent events.
+func (g *Game) drawDebug(s *Snapshot) {
+	if !s.ShowDebug {
+		return
+	}
+	fullWidth, fullHeight := g.Screen.Size()
+	style := tcell.StyleDefault.Foreground(tcell.ColorYellow)
+	line := g.Board.height + 2
+	draw := func(text string) {
+		for i, r := range text {
+			if 1+i >= fullWidth {
+				break
+			}
+			g.Screen.SetContent(1+i, line, r, nil, style)
+		}
+		line++
+	}
+
+	draw(fmt.Sprintf("tick %v  paused %v  over %v", s.Tick, s.Paused, s.Over))
+	for i, snake := range s.Snakes {
+		st := s.Stats[i]
+		head := snake.SnakeParts[0].Coordinate
+		text := fmt.Sprintf("P%v head (%v,%v) %-5v len %v score %v", i+1, head.x, head.y, directionNames[snake.Direction], len(snake.SnakeParts), snake.Score)
+		if st.dead {
+			text += " dead: " + st.CauseOfDeath
+		}
+		if snake.IsBot && st.planningRounds > 0 {
+			avg := st.planningTime / time.Duration(st.planningRounds)
+			text += fmt.Sprintf(" | bot last %v avg %v", st.lastPlanning, avg)
+		}
+		if snake.IsBot && st.Timeouts > 0 {
+			text += fmt.Sprintf(" timeouts %v", st.Timeouts)
+		}
+		draw(text)
+	}
+	for _, e := range s.Events {
+		if line >= fullHeight {
+			break
+		}
+		draw("> " + e)
+	}
+}
@@ -0,0 +1,132 @@
+package snake
+
+import (
+	"context"
+	"time"
+)
+
+// decisionRound is the state of the decision requests of the bots: the tick of
+// the last requests, their deadline, the bots that haven't answered them and
+// the answers that came in time.
+type decisionRound struct {
+	tick     int
+	timeout  time.Duration
+	deadline time.Time
+	pending  []bool
+	answers  []*BotDecision
+}
+
+func newDecisionRound(bots int, timeout time.Duration) decisionRound {
+	return decisionRound{
+		timeout: timeout,
+		pending: make([]bool, bots),
+		answers: make([]*BotDecision, bots),
+	}
+}
+
+// clear forgets the requests and answers of the round.
+func (r *decisionRound) clear() {
+	for i := range r.pending {
+		r.pending[i] = false
+		r.answers[i] = nil
+	}
+}
+
+// decisionDeadline is the time the bots have to decide in a tick: the one of
+// the settings, or four fifths of a tick.
+func (g *Game) decisionDeadline() time.Duration {
+	if g.settings.DecisionMs > 0 {
+		return time.Duration(g.settings.DecisionMs) * time.Millisecond
+	}
+	return g.Speed * 4 / 5
+}
+
+// requestDecisions queues a decision of every bot on the pool, all of them on
+// the same view of the snapshot after the tick. A bot still busy with an older
+// request gets none and misses the deadline. Runs in the engine.
+func (g *Game) requestDecisions(ctx context.Context, s *Snapshot) {
+	if g.bots == nil || s.Over {
+		return
+	}
+	round := &g.decisions
+	round.tick++
+	round.deadline = time.Now().Add(round.timeout)
+	view := g.botView(s)
+	g.bots.grow()
+	for i, slot := range g.bots.slots {
+		round.pending[i] = true
+		round.answers[i] = nil
+		g.bots.submit(botJob{
+			slot:     slot,
+			view:     view,
+			tick:     round.tick,
+			deadline: round.deadline,
+			done:     func(d BotDecision) { g.Post(ctx, d) },
+		})
+	}
+}
+
+// receiveDecision keeps the decision of a bot if it answered the last request
+// in time, it is applied with the others before the next tick. Late answers
+// are dropped and the snake keeps its direction. Runs in the engine.
+func (g *Game) receiveDecision(d BotDecision) {
+	round := &g.decisions
+	i := d.SnakeID - g.PlayerNumber
+	if i < 0 || i >= len(round.pending) {
+		return
+	}
+	if d.Tick != round.tick || !round.pending[i] || time.Now().After(round.deadline) {
+		g.logger.Debug("late bot decision dropped", "snake", d.SnakeID+1, "tick", d.Tick)
+		return
+	}
+	round.pending[i] = false
+	round.answers[i] = &d
+}
+
+// applyDecision turns a bot snake as it decided and stores what it planned.
+func (g *Game) applyDecision(d BotDecision) {
+	if d.Planned {
+		g.setBotPath(d.SnakeID, d.Path)
+	}
+	if d.Trace != nil {
+		g.setBotTrace(d.SnakeID, *d.Trace)
+	}
+	if d.Dir < Up || d.Dir > Down {
+		// the bot found no move, keep going straight
+		return
+	}
+	g.changeDirection(d.SnakeID, d.Dir)
+}
+
+// settleDecisions applies the answers of the last request in the order of the
+// snakes, so the arrival order doesn't change the game, and counts a timeout
+// for every bot that didn't answer in time. Runs in the engine before the
+// next tick.
+func (g *Game) settleDecisions() {
+	round := &g.decisions
+	for i, pending := range round.pending {
+		if d := round.answers[i]; d != nil {
+			round.answers[i] = nil
+			g.applyDecision(*d)
+			continue
+		}
+		if !pending {
+			continue
+		}
+		round.pending[i] = false
+		g.logger.Warn("bot missed its decision deadline", "snake", g.PlayerNumber+i+1, "tick", round.tick, "deadline", round.timeout)
+		g.recordTimeout(g.PlayerNumber + i)
+	}
+}
+
+// decision collects the decision of a bot and what it planned on a bot view.
+func (g *Game) decision(snakeNumber int, tick int, dir int) BotDecision {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	d := BotDecision{SnakeID: snakeNumber, Tick: tick, Dir: dir}
+	d.Path, d.Planned = g.BotPaths[snakeNumber]
+	if trace, ok := g.BotSearches[snakeNumber]; ok {
+		d.Trace = &trace
+	}
+	return d
+}
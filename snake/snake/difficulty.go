@@ -0,0 +1,105 @@
+package snake
+
+import "math/rand"
+
+// Difficulty is a named strength level of a bot snake. The reaction delay
+// and the mistakes weaken every bot, the look ahead and the survival checks
+// only the bots that plan ahead: the survival and opponent bots and the
+// search bot, which only uses the look ahead. The classic and hamilton bots
+// follow a fixed path, so their levels only differ in delay and mistakes.
+type Difficulty struct {
+	Name string
+	// ReactionDelay is the number of ticks a decision of the bot lags
+	// behind the board.
+	ReactionDelay int
+	// MistakeChance is the probability of a random move in a tick.
+	MistakeChance float64
+	// LookAhead is the number of ticks a survival or search bot simulates
+	// ahead, 0 means as far as the bot can.
+	LookAhead int
+	// SurvivalChecks tells if a survival bot makes sure it has room to live
+	// before it goes for food.
+	SurvivalChecks bool
+}
+
+// Difficulty levels of the bot settings.
+const (
+	DifficultyEasy   = "easy"
+	DifficultyNormal = "normal"
+	DifficultyHard   = "hard"
+	DifficultyExpert = "expert"
+)
+
+// Difficulties are the difficulty levels by name.
+var Difficulties = map[string]Difficulty{
+	DifficultyEasy:   {Name: DifficultyEasy, ReactionDelay: 2, MistakeChance: 0.15, LookAhead: 1, SurvivalChecks: false},
+	DifficultyNormal: {Name: DifficultyNormal, ReactionDelay: 1, MistakeChance: 0.05, LookAhead: 4, SurvivalChecks: true},
+	DifficultyHard:   {Name: DifficultyHard, ReactionDelay: 0, MistakeChance: 0.01, LookAhead: 8, SurvivalChecks: true},
+	DifficultyExpert: {Name: DifficultyExpert, ReactionDelay: 0, MistakeChance: 0, LookAhead: 0, SurvivalChecks: true},
+}
+
+// difficultyOf returns the difficulty level of a name, bots play at full
+// strength if the name is empty or unknown.
+func difficultyOf(name string) Difficulty {
+	if level, ok := Difficulties[name]; ok {
+		return level
+	}
+	return Difficulties[DifficultyExpert]
+}
+
+// difficultyBot weakens a bot with the reaction delay and the random
+// mistakes of its difficulty level.
+type difficultyBot struct {
+	bot     Bot
+	level   Difficulty
+	pending []int
+	seed    int64
+	rand    *rand.Rand
+}
+
+// withDifficulty wraps a bot if its level needs delays or mistakes, the
+// mistakes are drawn from seed. This is all the level does to the classic and
+// hamilton bots.
+func withDifficulty(bot Bot, level Difficulty, seed int64) Bot {
+	if level.ReactionDelay <= 0 && level.MistakeChance <= 0 {
+		return bot
+	}
+	return &difficultyBot{
+		bot:   bot,
+		level: level,
+		seed:  seed,
+		rand:  rand.New(rand.NewSource(seed)),
+	}
+}
+
+// reset forgets the decisions still delayed and starts the mistakes over, so
+// a new game doesn't play the moves of the last one.
+func (b *difficultyBot) reset() {
+	b.pending = nil
+	b.rand.Seed(b.seed)
+	if r, ok := b.bot.(resetter); ok {
+		r.reset()
+	}
+}
+
+func (b *difficultyBot) NextDirection(g *Game, snakeNumber int) int {
+	dir := b.bot.NextDirection(g, snakeNumber)
+	if b.rand.Float64() < b.level.MistakeChance {
+		_, current := g.snakeBody(snakeNumber)
+		moves := make([]int, 0, 3)
+		for _, m := range []int{Up, Left, Right, Down} {
+			if m != opposite(current) {
+				moves = append(moves, m)
+			}
+		}
+		dir = moves[b.rand.Intn(len(moves))]
+	}
+
+	b.pending = append(b.pending, dir)
+	if len(b.pending) <= b.level.ReactionDelay {
+		return -1
+	}
+	dir = b.pending[0]
+	b.pending = b.pending[1:]
+	return dir
+}
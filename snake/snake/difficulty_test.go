@@ -0,0 +1,68 @@
+package snake
+
+import "testing"
+
+// turnBot always turns the same way.
+type turnBot int
+
+func (b turnBot) NextDirection(g *Game, snakeNumber int) int { return int(b) }
+
+// TestDifficultyReset checks that a restarted bot doesn't play the decisions
+// it delayed in the last game.
+func TestDifficultyReset(t *testing.T) {
+	g := scenarioGame(t,
+		"#######",
+		"#aA...#",
+		"#.....#",
+		"#######",
+	)
+	level := Difficulty{ReactionDelay: 1, MistakeChance: 0.5}
+	b := withDifficulty(turnBot(Down), level, 1).(*difficultyBot)
+	var first []int
+	for i := 0; i < 8; i++ {
+		first = append(first, b.NextDirection(g, 0))
+	}
+	b.reset()
+	if len(b.pending) != 0 {
+		t.Fatalf("pending = %v after reset, want none", b.pending)
+	}
+	for i, want := range first {
+		if got := b.NextDirection(g, 0); got != want {
+			t.Errorf("decision %v = %v after reset, want %v", i, got, want)
+		}
+	}
+}
+
+// TestDifficultySeed checks that the mistakes of a bot come from the seed of
+// the game, so a scenario plays the same every time.
+func TestDifficultySeed(t *testing.T) {
+	var played [2][]int
+	for i := range played {
+		sc, err := ParseScenario(`seed 3
+bot a classic easy
+board
+##########
+#..aaA...#
+#........#
+#*.......#
+##########
+`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g, err := sc.NewGame()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b := g.newBot(0)
+		view := g.botView(g.Snapshot())
+		for tick := 0; tick < 40; tick++ {
+			played[i] = append(played[i], b.NextDirection(view, 0))
+		}
+	}
+	for tick := range played[0] {
+		if played[0][tick] != played[1][tick] {
+			t.Fatalf("decisions differ with the same seed:\n%v\n%v", played[0], played[1])
+		}
+	}
+}
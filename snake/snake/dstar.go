@@ -0,0 +1,292 @@
+package snake
+
+import "math"
+
+// dstarInf is the cost of a cell that can't reach the goal.
+const dstarInf = math.MaxInt32
+
+// dstarSearch is D* Lite, an incremental A*. It keeps its search tree between
+// searches and only repairs the cells whose costs changed since the last one.
+// A bot grid changes by a few cells per tick: the head advances, the tail
+// moves away and other snakes do the same, so most of the tree stays valid.
+//
+// The tree grows from the goal back to the start, a start that moved only
+// shifts the keys of the queued cells by km. A new goal or a grid of another
+// size starts the tree over, and so do changes to too many cells at once.
+type dstarSearch struct {
+	costs []int
+	// kinds are the tile kinds of the grid of the last search.
+	kinds  []int
+	width  int
+	height int
+	// g is the cost from a cell to the goal, rhs the one its neighbors
+	// offer. A cell whose g and rhs differ is queued.
+	g      []int
+	rhs    []int
+	queued []bool
+	key1   []int
+	key2   []int
+	queue  openHeap
+	start  int
+	goal   int
+	// last is the start of the last search, km the sum of the distances the
+	// start moved since the tree started.
+	last     Coordinate
+	km       int
+	valid    bool
+	expanded []int32
+	path     []Coordinate
+	grid     *Grid
+}
+
+func newDStarSearch() *dstarSearch {
+	return &dstarSearch{costs: NewGridPather().costs}
+}
+
+func (s *dstarSearch) Path(gr *Grid, from, to Coordinate) ([]Coordinate, int, bool) {
+	s.grid = gr
+	s.expanded = s.expanded[:0]
+	if from == to && gr.Inside(from) {
+		s.path = append(s.path[:0], from)
+		return s.path, 0, true
+	}
+	if !gr.Inside(from) || !gr.Inside(to) || !gr.Walkable(gr.Index(to)) {
+		return nil, 0, false
+	}
+	start, goal := gr.Index(from), gr.Index(to)
+	if !s.valid || gr.Width != s.width || gr.Height != s.height || goal != s.goal || !s.repair(gr, from) {
+		s.reset(gr, from, goal)
+	}
+	s.shortestPath(gr)
+	if s.rhs[start] >= dstarInf {
+		return nil, 0, false
+	}
+
+	// follow the cheapest neighbors down to the goal
+	s.path = append(s.path[:0], from)
+	for i := start; i != goal; {
+		next, best := -1, dstarInf
+		for dir := Up; dir <= Down; dir++ {
+			j, ok := neighborCell(gr, i, dir)
+			if !ok || !gr.Walkable(j) || s.g[j] >= dstarInf {
+				continue
+			}
+			if c := s.cost(gr.Kinds[j]) + s.g[j]; c < best {
+				next, best = j, c
+			}
+		}
+		if next < 0 || len(s.path) > len(s.kinds) {
+			// can't happen on a repaired tree, but don't loop forever
+			s.valid = false
+			return nil, 0, false
+		}
+		s.path = append(s.path, gr.Coordinate(next))
+		i = next
+	}
+	return s.path, s.rhs[start], true
+}
+
+// reset starts a new tree from the goal.
+func (s *dstarSearch) reset(gr *Grid, from Coordinate, goal int) {
+	size := len(gr.Kinds)
+	if len(s.g) != size {
+		s.kinds = make([]int, size)
+		s.g = make([]int, size)
+		s.rhs = make([]int, size)
+		s.queued = make([]bool, size)
+		s.key1 = make([]int, size)
+		s.key2 = make([]int, size)
+	}
+	copy(s.kinds, gr.Kinds)
+	for i := range s.g {
+		s.g[i] = dstarInf
+		s.rhs[i] = dstarInf
+		s.queued[i] = false
+	}
+	s.queue = s.queue[:0]
+	s.width, s.height = gr.Width, gr.Height
+	s.start, s.goal = gr.Index(from), goal
+	s.last, s.km = from, 0
+	s.valid = true
+	s.rhs[goal] = 0
+	s.push(goal)
+}
+
+// repair moves the start and updates the cells around every cell whose cost
+// changed since the last search. It is false if too many cells changed and
+// the tree should start over.
+func (s *dstarSearch) repair(gr *Grid, from Coordinate) bool {
+	oldStart := s.start
+	s.km += manhattan(s.last, from)
+	s.last = from
+	s.start = gr.Index(from)
+	s.update(gr, oldStart)
+	s.update(gr, s.start)
+
+	changed := 0
+	for i, kind := range gr.Kinds {
+		old := s.kinds[i]
+		if old == kind {
+			continue
+		}
+		s.kinds[i] = kind
+		if s.entry(old) == s.entry(kind) {
+			continue
+		}
+		changed++
+		if changed > len(s.kinds)/8 {
+			return false
+		}
+		s.update(gr, i)
+		for dir := Up; dir <= Down; dir++ {
+			if j, ok := neighborCell(gr, i, dir); ok {
+				s.update(gr, j)
+			}
+		}
+	}
+	if len(s.queue) > 4*len(s.kinds) {
+		s.compact()
+	}
+	return true
+}
+
+// shortestPath expands cells until the start is consistent and no queued
+// cell can lead to a cheaper path to it.
+func (s *dstarSearch) shortestPath(gr *Grid) {
+	for {
+		top, ok := s.top()
+		if !ok {
+			return
+		}
+		start1, start2 := s.key(s.start)
+		if !keyLess(top.fCost, top.hCost, start1, start2) && s.rhs[s.start] <= s.g[s.start] {
+			return
+		}
+		u := int(top.i)
+		s.queue.pop()
+		if k1, k2 := s.key(u); keyLess(top.fCost, top.hCost, k1, k2) {
+			// the start moved since the cell was queued
+			s.push(u)
+			continue
+		}
+		s.queued[u] = false
+		s.expanded = append(s.expanded, top.i)
+		if s.g[u] > s.rhs[u] {
+			s.g[u] = s.rhs[u]
+		} else {
+			s.g[u] = dstarInf
+			s.update(gr, u)
+		}
+		for dir := Up; dir <= Down; dir++ {
+			if j, ok := neighborCell(gr, u, dir); ok {
+				s.update(gr, j)
+			}
+		}
+	}
+}
+
+// update recalculates the rhs of a cell and queues it if it is inconsistent.
+// Only the start and the walkable cells lead anywhere, the others are dead
+// ends.
+func (s *dstarSearch) update(gr *Grid, u int) {
+	if u != s.goal {
+		best := dstarInf
+		if u == s.start || gr.Walkable(u) {
+			for dir := Up; dir <= Down; dir++ {
+				j, ok := neighborCell(gr, u, dir)
+				if !ok || !gr.Walkable(j) || s.g[j] >= dstarInf {
+					continue
+				}
+				if c := s.cost(gr.Kinds[j]) + s.g[j]; c < best {
+					best = c
+				}
+			}
+		}
+		s.rhs[u] = best
+	}
+	if s.g[u] != s.rhs[u] {
+		s.push(u)
+	} else {
+		s.queued[u] = false
+	}
+}
+
+// key is the priority of a cell: the estimated cost of a path from the start
+// through it, and its cost to the goal for ties.
+func (s *dstarSearch) key(i int) (int, int) {
+	m := s.g[i]
+	if s.rhs[i] < m {
+		m = s.rhs[i]
+	}
+	if m >= dstarInf {
+		return dstarInf, dstarInf
+	}
+	return m + manhattan(s.last, s.grid.Coordinate(i)) + s.km, m
+}
+
+// push queues a cell with its current key. An older entry of the cell stays
+// in the queue and is skipped by top.
+func (s *dstarSearch) push(i int) {
+	k1, k2 := s.key(i)
+	s.queued[i] = true
+	s.key1[i], s.key2[i] = k1, k2
+	s.queue.push(openCell{i: int32(i), fCost: k1, hCost: k2})
+}
+
+// top drops the stale entries of the queue and returns the first one, false
+// if the queue is empty.
+func (s *dstarSearch) top() (openCell, bool) {
+	for len(s.queue) > 0 {
+		e := s.queue[0]
+		i := int(e.i)
+		if s.queued[i] && e.fCost == s.key1[i] && e.hCost == s.key2[i] {
+			return e, true
+		}
+		s.queue.pop()
+	}
+	return openCell{}, false
+}
+
+// compact rebuilds the queue without its stale entries.
+func (s *dstarSearch) compact() {
+	s.queue = s.queue[:0]
+	for i, queued := range s.queued {
+		if queued {
+			s.queue.push(openCell{i: int32(i), fCost: s.key1[i], hCost: s.key2[i]})
+		}
+	}
+}
+
+// entry returns the cost of entering a tile kind, -1 for blockers.
+func (s *dstarSearch) entry(kind int) int {
+	if kind == KindBlocker {
+		return -1
+	}
+	return s.cost(kind)
+}
+
+func (s *dstarSearch) cost(kind int) int {
+	if kind < len(s.costs) && s.costs[kind] > 0 {
+		return s.costs[kind]
+	}
+	return 1
+}
+
+func (s *dstarSearch) Trace() SearchTrace {
+	trace := SearchTrace{Expanded: make([]Coordinate, 0, len(s.expanded))}
+	if s.grid == nil {
+		return trace
+	}
+	for _, i := range s.expanded {
+		trace.Expanded = append(trace.Expanded, s.grid.Coordinate(int(i)))
+	}
+	return trace
+}
+
+// keyLess compares two keys of the D* Lite queue.
+func keyLess(a1, a2, b1, b2 int) bool {
+	if a1 != b1 {
+		return a1 < b1
+	}
+	return a2 < b2
+}
@@ -0,0 +1,119 @@
+package snake
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestDStarSearch(t *testing.T) {
+	tests := []struct {
+		name     string
+		rows     []string
+		found    bool
+		distance int
+	}{
+		{"straight", []string{
+			"#######",
+			"#F...T#",
+			"#######",
+		}, true, 4},
+		{"around a wall", []string{
+			"#######",
+			"#F.#.T#",
+			"#..#..#",
+			"#.....#",
+			"#######",
+		}, true, 8},
+		{"around mud", []string{
+			"#######",
+			"#F%%%T#",
+			"#.....#",
+			"#######",
+		}, true, 6},
+		{"through mud", []string{
+			"#######",
+			"#F%%%T#",
+			"#######",
+		}, true, 7},
+		{"walled in", []string{
+			"#######",
+			"#F.#.T#",
+			"#######",
+		}, false, 0},
+		{"start is the goal", []string{
+			"###",
+			"#F#",
+			"###",
+		}, true, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gr, from, to := textGrid(tt.rows...)
+			if tt.name == "start is the goal" {
+				to = from
+			}
+			path, distance, found := newDStarSearch().Path(gr, from, to)
+			if found != tt.found {
+				t.Fatalf("found = %v, want %v", found, tt.found)
+			}
+			if !found {
+				return
+			}
+			if distance != tt.distance {
+				t.Errorf("distance = %v, want %v", distance, tt.distance)
+			}
+			checkPath(t, gr, path, from, to, distance)
+		})
+	}
+}
+
+// TestDStarSearchReplan checks that the repaired tree finds paths as cheap as
+// a new A* search does, on the ticks of a snake moving to its goal and on
+// grids that change at random between searches.
+func TestDStarSearchReplan(t *testing.T) {
+	for _, board := range searchBoards {
+		t.Run(board.name, func(t *testing.T) {
+			grids, heads, goal := replanGrids(board.build())
+			dstar, astar := newDStarSearch(), NewGridPather()
+			for tick, gr := range grids {
+				_, want, wantFound := astar.Path(gr, heads[tick], goal)
+				path, distance, found := dstar.Path(gr, heads[tick], goal)
+				if found != wantFound || distance != want {
+					t.Fatalf("tick %v: got %v %v, A* %v %v", tick, distance, found, want, wantFound)
+				}
+				if found {
+					checkPath(t, gr, path, heads[tick], goal, distance)
+				}
+			}
+		})
+	}
+
+	t.Run("random", func(t *testing.T) {
+		r := rand.New(rand.NewSource(1))
+		gr := NewGrid(22, 22)
+		gr.Reset()
+		dstar, astar := newDStarSearch(), NewGridPather()
+		kinds := []int{KindPlain, KindPlain, KindBlocker, KindMud}
+		for search := 0; search < 2000; search++ {
+			if search%100 == 0 {
+				gr.Reset()
+			}
+			for k := r.Intn(8); k > 0; k-- {
+				gr.Set(newCoordinate(1+r.Intn(20), 1+r.Intn(20)), kinds[r.Intn(len(kinds))])
+			}
+			from := newCoordinate(1+r.Intn(20), 1+r.Intn(20))
+			to := newCoordinate(1+r.Intn(4), 1+r.Intn(4))
+			if gr.Kind(from) == KindBlocker || gr.Kind(to) == KindBlocker {
+				continue
+			}
+			_, want, wantFound := astar.Path(gr, from, to)
+			path, distance, found := dstar.Path(gr, from, to)
+			if found != wantFound || distance != want {
+				t.Fatalf("search %v from %v to %v: got %v %v, A* %v %v", search, from, to, distance, found, want, wantFound)
+			}
+			if found {
+				checkPath(t, gr, path, from, to, distance)
+			}
+		}
+	})
+}
@@ -0,0 +1,178 @@
+package snake
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gdamore/tcell"
+)
+
+// eventQueueSize is the number of events that can wait for the engine.
+const eventQueueSize = 64
+
+// An Event is something the engine of a game reacts to. Input sources, bots,
+// the network and timers post events into the queue of the game with Post,
+// and the engine consumes them in order in Run.
+type Event interface {
+	isEvent()
+}
+
+// DirectionChange asks to turn a snake.
+type DirectionChange struct {
+	SnakeID int
+	Dir     int
+}
+
+// Tick moves the game one step forward.
+type Tick struct{}
+
+// Pause pauses or resumes the game.
+type Pause struct{}
+
+// Start starts the game from the start screen.
+type Start struct{}
+
+// Restart starts a new game after the game is over.
+type Restart struct{}
+
+// Quit stops the game.
+type Quit struct{}
+
+// ToggleHeatmap shows or hides the searches of the bots.
+type ToggleHeatmap struct{}
+
+// ToggleDebug shows or hides the debug panel.
+type ToggleDebug struct{}
+
+// DumpScenario saves the game as a scenario file.
+type DumpScenario struct{}
+
+// NameInput is a key typed into the name of a new high score.
+type NameInput struct {
+	Key *tcell.EventKey
+}
+
+// BotDecision is the answer of a bot to the decision request of a tick.
+type BotDecision struct {
+	SnakeID int
+	Tick    int
+	Dir     int
+	// Path and Trace are what the bot planned, for display. Planned tells if
+	// it set a path at all.
+	Path    []Coordinate
+	Planned bool
+	Trace   *SearchTrace
+}
+
+func (DirectionChange) isEvent() {}
+func (Tick) isEvent()            {}
+func (Pause) isEvent()           {}
+func (Start) isEvent()           {}
+func (Restart) isEvent()         {}
+func (Quit) isEvent()            {}
+func (ToggleHeatmap) isEvent()   {}
+func (ToggleDebug) isEvent()     {}
+func (DumpScenario) isEvent()    {}
+func (NameInput) isEvent()       {}
+func (BotDecision) isEvent()     {}
+
+// Post puts an event into the queue of the engine. It waits while the queue is
+// full and gives up when ctx is done, it tells if the event was queued.
+func (g *Game) Post(ctx context.Context, e Event) bool {
+	select {
+	case g.queue <- e:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Run is the engine of the game: it consumes the events of the queue in order
+// until ctx is done or a Quit event arrives. Only the engine changes the game,
+// after every event it publishes a snapshot and draws it, unless the game is
+// headless and has no screen. After a tick the bots decide on that snapshot.
+func (g *Game) Run(ctx context.Context) {
+	for {
+		var e Event
+		select {
+		case <-ctx.Done():
+			return
+		case e = <-g.queue:
+		}
+		g.logger.Debug("event", "type", fmt.Sprintf("%T", e), "value", e)
+		ticked := false
+
+		switch e := e.(type) {
+		case DirectionChange:
+			g.changeDirection(e.SnakeID, e.Dir)
+		case Tick:
+			if g.shouldContinue() {
+				g.settleDecisions()
+				g.updateItemState()
+				ticked = true
+			}
+		case BotDecision:
+			g.receiveDecision(e)
+		case Pause:
+			g.Pause()
+		case Start:
+			if !g.hasStarted() {
+				g.start()
+			}
+		case Restart:
+			if g.hasEnded() {
+				g.reStart()
+			}
+		case ToggleHeatmap:
+			g.toggleHeatmap()
+		case ToggleDebug:
+			g.toggleDebug()
+		case DumpScenario:
+			g.dumpScenario()
+		case NameInput:
+			g.handleNameInput(e.Key)
+		case Quit:
+			g.exit()
+			return
+		}
+		s := g.publish()
+		if ticked {
+			g.requestDecisions(ctx, s)
+		}
+		if !g.headless {
+			g.updateScreen()
+		}
+	}
+}
+
+// runTicker posts a Tick event at the speed of the game.
+func (g *Game) runTicker(ctx context.Context) {
+	ticker := time.NewTicker(g.Speed)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			g.Post(ctx, Tick{})
+		}
+	}
+}
+
+// changeDirection turns a snake if it doesn't turn back into itself.
+func (g *Game) changeDirection(snakeID int, dir int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if snakeID < 0 || snakeID >= len(g.Snakes) || dir < Up || dir > Down {
+		return
+	}
+	if g.Board.Terrain(g.Snakes[snakeID].SnakeParts[0].Coordinate) == KindIce {
+		// no grip to turn on ice
+		return
+	}
+	if g.shouldUpdateDirection(g.Snakes[snakeID].Direction, dir) {
+		g.Snakes[snakeID].Direction = dir
+		g.recordTurn(snakeID)
+	}
+}
@@ -0,0 +1,66 @@
+package snake
+
+// floodFill measures the room that is reachable on a grid. Its buffers are
+// reused between fills.
+type floodFill struct {
+	seen  []uint32
+	gen   uint32
+	queue []int32
+}
+
+// count returns the number of walkable cells reachable from a coordinate, the
+// start itself is not counted. target is reachable even if it is a blocker,
+// reached tells if it was found. With a positive limit the fill stops once
+// limit cells are counted.
+func (f *floodFill) count(gr *Grid, from Coordinate, target Coordinate, limit int) (area int, reached bool) {
+	size := gr.Width * gr.Height
+	if len(f.seen) < size {
+		f.seen = make([]uint32, size)
+		f.gen = 0
+	}
+	f.gen++
+	if f.gen == 0 {
+		for i := range f.seen {
+			f.seen[i] = 0
+		}
+		f.gen = 1
+	}
+	if !gr.Inside(from) {
+		return 0, false
+	}
+
+	goal := -1
+	if gr.Inside(target) {
+		goal = gr.Index(target)
+	}
+	start := gr.Index(from)
+	f.seen[start] = f.gen
+	f.queue = append(f.queue[:0], int32(start))
+	for head := 0; head < len(f.queue); head++ {
+		i := int(f.queue[head])
+		x, y := i%gr.Width, i/gr.Width
+		for _, offset := range [4][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+			nx, ny := x+offset[0], y+offset[1]
+			if nx < 0 || ny < 0 || nx >= gr.Width || ny >= gr.Height {
+				continue
+			}
+			j := ny*gr.Width + nx
+			if f.seen[j] == f.gen {
+				continue
+			}
+			f.seen[j] = f.gen
+			if j == goal {
+				reached = true
+			}
+			if !gr.Walkable(j) {
+				continue
+			}
+			area++
+			if limit > 0 && area >= limit {
+				return area, reached
+			}
+			f.queue = append(f.queue, int32(j))
+		}
+	}
+	return area, reached
+}
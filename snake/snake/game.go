@@ -1,12 +1,12 @@
 package snake
 
 import (
+	"context"
 	"fmt"
-	"log"
 	"math/rand"
 	"os"
-	"reflect"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gdamore/tcell"
@@ -19,84 +19,132 @@ const (
 	Down
 )
 
+// Food types.
+const (
+	FoodNormal = "normal"
+	FoodBonus  = "bonus"
+)
+
+// bonusChance is one in how many new foods is bonus food, which is worth more
+// points.
+const bonusChance = 5
+
 type Food struct {
 	Coordinates Coordinate
 	Letter      string
 	Point       int
-	//TODO points, type, etc
+	Type        string
 }
 
 type Game struct {
-	mu             sync.Mutex
-	Screen         tcell.Screen
-	IsStart        bool
-	IsOver         bool
-	IsPaused       bool
-	Food           []Food
-	Board          *Board
-	Speed          time.Duration
-	Snakes         []*Snake
-	PlayerNumber   int
-	FoodNumber     int
-	BotNumber      int
-	whoLost        int
-	BotPaths       map[int][]Coordinate
-	settings       PlayersControlSettings
-	TestFieldError string
-	TestFields     []string
-}
-
-func StartGame(playerNumber int, foodNumber int, botNumber int) {
-	playerDirChan := make([]chan int, 0)
-	for i := 0; i < playerNumber; i++ {
-		playerDirChan = append(playerDirChan, make(chan int, 1))
-	}
-
-	botDirChan := make([]chan int, 0)
-	botRunBotChan := make([]chan bool, 0)
-	for i := 0; i < botNumber; i++ {
-		botDirChan = append(botDirChan, make(chan int, 1))
-		botRunBotChan = append(botRunBotChan, make(chan bool, 1))
-	}
-
-	game := newGame(newBoard(50, 20), playerNumber, foodNumber, botNumber)
-
-	// go game.Run(directionChan1, directionChan2, directionChanBot1, runBotCalcChan1, directionChanBot2, runBotCalcChan2)
-	// go game.Run(directionChan1, directionChan2, botDirChan[0], botRunBotChan[0], directionChanBot2, runBotCalcChan2)
-	go game.Run2(playerDirChan, botDirChan, botRunBotChan)
-	go game.handleKeyBoardEvents(playerDirChan)
-
-	// for i := playerNumber; i < len(game.Snakes); i++ {
-	// 	go game.botControl(game.Snakes[i], botDirChan[i-playerNumber], botRunBotChan[i-playerNumber])
-	// }
-
-	//összes botnak egy chanel?
-	//go game.botControl(game.Snakes[0], directionChanBot1, runBotCalcChan1)
-	//go game.botControl(game.Snakes[1], directionChanBot2, runBotCalcChan2)
-
-	for i := 0; i < botNumber; i++ {
-		go game.botControl(game.Snakes[i+playerNumber], botDirChan[i], botRunBotChan[i], i+playerNumber)
-	}
-
-	// go game.botControl(game.Snakes[0], botDirChan[0], botRunBotChan[0])
-
-	for {
-	}
-}
-
-func newGame(board *Board, playerNumber int, foodNumber int, botNumber int) *Game {
+	mu           sync.Mutex
+	Screen       tcell.Screen
+	IsStart      bool
+	IsOver       bool
+	IsPaused     bool
+	Food         []Food
+	Board        *Board
+	Speed        time.Duration
+	Snakes       []*Snake
+	PlayerNumber int
+	FoodNumber   int
+	BotNumber    int
+	whoLost      int
+	BotPaths     map[int][]Coordinate
+	BotSearches  map[int]SearchTrace
+	ShowHeatmap  bool
+	settings     PlayersControlSettings
+	ShowDebug    bool
+	events       []string
+	logger       *Logger
+	logFile      *os.File
+	cancel       context.CancelFunc
+	queue        chan Event
+	bots         *botPool
+	decisions    decisionRound
+	snapshot     atomic.Pointer[Snapshot]
+	rand         *rand.Rand
+	// seed is the seed of rand, the bots derive their seeds from it.
+	seed int64
+	// headless games have no screen and save nothing, like the games of
+	// scenarios.
+	headless bool
+	// occupied is the occupancy grid of the snakes and food.
+	occupied *Occupancy
+	// botGrid is the grid of the snapshot of a bot view, see fillGrid.
+	botGrid *Grid
+	// teams are the planners of the bot teams by name.
+	teams map[string]*teamPlanner
+
+	HighScores      []HighScore
+	highScoreMode   string
+	highScorePath   string
+	highScoreStatus string
+	nameEntries     []int
+	nameInput       string
+
+	stats       *GameStats
+	statsStatus string
+}
+
+// StartGame runs a game in the terminal until the players quit.
+func StartGame(playerNumber int, foodNumber int, botNumber int) error {
+	return RunGame(context.Background(), playerNumber, foodNumber, botNumber)
+}
+
+// RunGame runs a game in the terminal until the players quit or ctx is
+// cancelled. Every goroutine of the game has ended and the terminal is
+// restored when it returns.
+func RunGame(ctx context.Context, playerNumber int, foodNumber int, botNumber int) error {
+	game, err := newGame(newBoard(50, 20), playerNumber, foodNumber, botNumber)
+	if err != nil {
+		return err
+	}
+	ctx, game.cancel = context.WithCancel(ctx)
+	defer game.cancel()
+
+	var wg sync.WaitGroup
+	run := func(f func()) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			f()
+		}()
+	}
+	bots := make([]Bot, botNumber)
+	for i := range bots {
+		bots[i] = game.newBot(i)
+	}
+	game.bots = newBotPool(game, bots)
+	game.bots.start(ctx, run)
+	game.decisions = newDecisionRound(botNumber, game.decisionDeadline())
+	run(func() { game.Run(ctx) })
+	run(func() { game.runTicker(ctx) })
+	run(func() { game.handleKeyBoardEvents(ctx) })
+
+	<-ctx.Done()
+	// finishing the screen restores the terminal and makes the blocked
+	// PollEvent return
+	game.Screen.Fini()
+	wg.Wait()
+	game.closeLog()
+	return nil
+}
+
+func newGame(board *Board, playerNumber int, foodNumber int, botNumber int) (*Game, error) {
 	screen, err := tcell.NewScreen()
 
 	if err != nil {
-		log.Fatalf("%+v", err)
+		return nil, err
 	}
 	if err := screen.Init(); err != nil {
-		log.Fatalf("%+v", err)
+		return nil, err
 	}
 
 	defStyle := tcell.StyleDefault.Background(tcell.ColorBlack).Foreground(tcell.ColorWhite)
 	screen.SetStyle(defStyle)
 
+	seed := time.Now().UnixNano()
 	game := &Game{
 		Board:        board,
 		Screen:       screen,
@@ -106,208 +154,131 @@ func newGame(board *Board, playerNumber int, foodNumber int, botNumber int) *Gam
 		PlayerNumber: playerNumber,
 		FoodNumber:   foodNumber,
 		BotNumber:    botNumber,
-		TestFields:   make([]string, 0),
 		BotPaths:     make(map[int][]Coordinate),
+		BotSearches:  make(map[int]SearchTrace),
+		queue:        make(chan Event, eventQueueSize),
+		rand:         rand.New(rand.NewSource(seed)),
+		seed:         seed,
 	}
-	game.settings = controlls("playerControlSettings.json")
-
-	game.TestFieldError = ""
-	for i := 0; i < 6; i++ {
-		game.TestFields = append(game.TestFields, "")
+	if logger, logFile, err := openLog(); err == nil {
+		game.logger, game.logFile = logger, logFile
 	}
+	game.settings = game.controlls("playerControlSettings.json")
+	game.Board.addTerrains(game.rand, game.settings.Terrain)
+	game.initHighScores()
 
 	//bot snake
 	game.createSnakes()
+	game.buildOccupancy()
+	game.stats = newGameStats(game.highScoreMode, game.Snakes)
 
 	for i := 0; i < game.FoodNumber; i++ {
 		game.setNewFoodPosition()
 	}
+	game.publish()
 
-	return game
-}
-
-func (game *Game) Run(directionChan1 chan int, directionChan2 chan int, directionChanBot1 chan int, runBotCalcChan1 chan bool, directionChanBot2 chan int, runBotCalcChan2 chan bool) {
-	ticker := time.NewTicker(game.Speed)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case newDirection0 := <-directionChan1:
-			if game.shouldUpdateDirection(game.Snakes[0].Direction, newDirection0) {
-				game.mu.Lock()
-				game.Snakes[0].Direction = newDirection0
-				// game.TestField1 = fmt.Sprintln("nor dir: ", newDirection0)
-				game.mu.Unlock()
-			}
-		// case newDirection1 := <-directionChan2:
-		// 	if game.shouldUpdateDirection(game.Snakes[1].Direction, newDirection1) {
-		// 		game.mu.Lock()
-		// 		game.Snakes[1].Direction = newDirection1
-		// 		game.mu.Unlock()
-		// 	}
-
-		case newDirectionBot := <-directionChanBot1:
-			game.mu.Lock()
-			game.Snakes[0].Direction = newDirectionBot
-			game.mu.Unlock()
-
-		case newDirectionBot := <-directionChanBot2:
-			game.mu.Lock()
-			game.Snakes[1].Direction = newDirectionBot
-			game.mu.Unlock()
-
-		case <-ticker.C:
-			if game.shouldContinue() {
-				game.updateItemState()
-				runBotCalcChan1 <- true
-				// runBotCalcChan2 <- true
-			}
-			game.updateScreen()
-		}
-	}
-}
-
-func (game *Game) Run2(playerDirChan []chan int, botDirChans []chan int, botRunChanes []chan bool) {
-	ticker := time.NewTicker(game.Speed)
-	defer ticker.Stop()
-
-	cases := make([]reflect.SelectCase, len(playerDirChan)+len(botDirChans)+1)
-	//PLAYER CHANs
-	if len(playerDirChan) > 0 {
-		for i, ch := range playerDirChan {
-			cases[i] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ch)}
-		}
-	}
-	//BOT CHANs
-	for i, ch := range botDirChans {
-		j := i + len(playerDirChan)
-		cases[j] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ch)}
-	}
-	//TIMER CHAN
-	tc := make(chan time.Time, 1)
-	go func() {
-		for {
-			if !game.IsOver && !game.isPaused() {
-				tc <- <-ticker.C
-			}
-		}
-	}()
-	cases[len(cases)-1] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(tc)}
-
-	// game.TestField1 = fmt.Sprintf("len %v", len(cases))
-
-	for {
-		chosen, value, _ := reflect.Select(cases)
-		game.TestFieldError = fmt.Sprintf("Read from channel %#v and received %v\n", chosen, value)
-
-		if chosen != len(cases)-1 {
-			if game.shouldUpdateDirection(game.Snakes[chosen].Direction, value.Interface().(int)) {
-				game.mu.Lock()
-				game.Snakes[chosen].Direction = value.Interface().(int)
-				game.mu.Unlock()
-			}
-		} else {
-			if game.shouldContinue() {
-				game.updateItemState()
-				for _, v := range botRunChanes {
-					v <- true
-				}
-			}
-			game.updateScreen()
-		}
-	}
+	return game, nil
 }
 
-func newFood(x int, y int) Food {
+func newFood(x int, y int, bonus bool) Food {
 	var food Food
 	//Ascii A-Z
-	// minCap := 65
-	// maxCap := 90
+	minCap := 65
+	maxCap := 90
 	//Ascii a-z
 	minNor := 96
 	maxNor := 122
 
-	// rNumber := rand.Intn(10)
-
-	// if rNumber < 2 {
-	// 	food = Food{
-	// 		Coordinates: newCoordinate(x, y),
-	// 		Letter:      string(rand.Intn(maxCap-minCap+1) + minCap),
-	// 		Point:       5,
-	// 	}
-	// } else {
-	food = Food{
-		Coordinates: newCoordinate(x, y),
-		Letter:      string(rand.Intn(maxNor-minNor+1) + minNor),
-		Point:       1,
+	if bonus {
+		food = Food{
+			Coordinates: newCoordinate(x, y),
+			Letter:      string(rune(rand.Intn(maxCap-minCap+1) + minCap)),
+			Point:       5,
+			Type:        FoodBonus,
+		}
+	} else {
+		food = Food{
+			Coordinates: newCoordinate(x, y),
+			Letter:      string(rune(rand.Intn(maxNor-minNor+1) + minNor)),
+			Point:       1,
+			Type:        FoodNormal,
+		}
 	}
-	// }
 
 	return food
 }
 
 //----------Control-----------------------------------------------------------
 
-func (game *Game) handleKeyBoardEvents(directionChanArray []chan int) {
-	defer func() {
-		for i := 0; i < len(directionChanArray); i++ {
-			close(directionChanArray[i])
-		}
-	}()
-
+func (game *Game) handleKeyBoardEvents(ctx context.Context) {
 	for {
 		switch event := game.Screen.PollEvent().(type) {
+		case nil:
+			// the screen was finished
+			return
 		case *tcell.EventResize:
 			game.resizeScreen()
 		case *tcell.EventKey:
+			s := game.Snapshot()
 			if event.Key() == tcell.KeyEscape || event.Key() == tcell.KeyCtrlC {
-				game.exit()
+				game.Post(ctx, Quit{})
 			}
-			if !game.hasStarted() && event.Key() == tcell.KeyEnter {
-				game.start()
+			if !s.Started && event.Key() == tcell.KeyEnter {
+				game.Post(ctx, Start{})
 			}
-			if !game.hasEnded() {
+			if s.NameEntry >= 0 {
+				game.Post(ctx, NameInput{Key: event})
+			} else if !s.Over {
 				for i := 0; i < game.PlayerNumber; i++ {
 					if string(event.Rune()) == game.settings.PlayersControlSettings[i].Left {
-						directionChanArray[i] <- Left
+						game.Post(ctx, DirectionChange{SnakeID: i, Dir: Left})
 					}
 					if string(event.Rune()) == game.settings.PlayersControlSettings[i].Right {
-						directionChanArray[i] <- Right
+						game.Post(ctx, DirectionChange{SnakeID: i, Dir: Right})
 					}
 					if string(event.Rune()) == game.settings.PlayersControlSettings[i].Down {
-						directionChanArray[i] <- Down
+						game.Post(ctx, DirectionChange{SnakeID: i, Dir: Down})
 					}
 					if string(event.Rune()) == game.settings.PlayersControlSettings[i].Up {
-						directionChanArray[i] <- Up
+						game.Post(ctx, DirectionChange{SnakeID: i, Dir: Up})
 					}
 				}
 				if event.Key() == tcell.KeyBackspace {
-					game.Pause()
+					game.Post(ctx, Pause{})
+				}
+				if event.Key() == tcell.KeyF2 {
+					game.Post(ctx, ToggleHeatmap{})
+				}
+				if event.Key() == tcell.KeyF3 {
+					game.Post(ctx, ToggleDebug{})
+				}
+				if event.Key() == tcell.KeyF4 {
+					game.Post(ctx, DumpScenario{})
 				}
 			} else {
 				if event.Rune() == 'y' {
-					game.reStart()
+					game.Post(ctx, Restart{})
 				}
 				if event.Rune() == 'n' {
-					game.exit()
+					game.Post(ctx, Quit{})
 				}
 			}
 		}
 	}
 }
 
+// setNewFoodPosition puts a food on a free cell, every free cell with the same
+// chance, and makes it bonus food by chance. No food is added if the board is
+// full.
 func (g *Game) setNewFoodPosition() {
-	var availableCoordinates []Coordinate
-
-	for _, coordinates := range g.Board.area {
-		for _, snake := range g.Snakes {
-			if !snake.contains(coordinates) {
-				availableCoordinates = append(availableCoordinates, coordinates)
-			}
-		}
+	foodPosition, ok := g.occupied.RandomFree(g.rand)
+	if !ok {
+		g.logger.Warn("board is full, no food placed")
+		return
 	}
-	foodPosition := availableCoordinates[rand.Intn(len(availableCoordinates))]
-	g.Food = append(g.Food, newFood(foodPosition.x, foodPosition.y))
+	bonus := g.rand.Intn(bonusChance) == 0
+	g.Food = append(g.Food, newFood(foodPosition.x, foodPosition.y, bonus))
+	g.occupied.addFood(foodPosition)
 }
 
 func (g *Game) createSnakes() {
@@ -340,40 +311,64 @@ func (g *Game) reCreateSnakes() {
 
 func (g *Game) updateItemState() {
 	for i, currentSnake := range g.Snakes {
+		if currentSnake.skip > 0 {
+			// stuck in mud
+			currentSnake.skip--
+			continue
+		}
 
-		if currentSnake.canMove(g.Board, g.Snakes) {
+		if cause := g.collision(i); cause == "" {
+			tail := currentSnake.SnakeParts[len(currentSnake.SnakeParts)-1].Coordinate
 			currentSnake.move()
+			// a growing snake leaves its tail, it drops a part placed off the arena
+			g.occupied.vacate(tail)
+			g.occupied.occupy(currentSnake.SnakeParts[0].Coordinate, i)
+			if g.Board.Terrain(currentSnake.SnakeParts[0].Coordinate) == KindMud {
+				currentSnake.skip = mudSkip
+			}
 
 			for _, food := range g.Food {
 				if currentSnake.CanEat(&food) {
 					currentSnake.eat(&food)
+					g.recordFood(i, food)
+					g.event("food eaten", "snake", i+1, "type", food.Type, "length", len(currentSnake.SnakeParts))
 					g.removeAndAddFood(food)
 				}
 			}
 		} else {
-			// ez valamiért megoldja az egyszerre lépünk egy mezőre problémát
-			//valószínüleg azért mert removeolja az ütközés pillanatába, de nem menti el ezt és következő ciklusba ütközés előttről folytatja
-			// if len(g.Snakes) > 1 {
-			// 	removeElementFromSlice(g.Snakes, g.Snakes[i])
-			// } else {
+			g.recordDeath(i, cause)
+			g.event("snake died", "snake", i+1, "cause", cause, "score", currentSnake.Score)
 			g.over(i)
-			// }
 		}
 	}
+	g.recordTick()
+	if g.hasEnded() {
+		g.finishStats()
+	}
 }
 
-func removeElementFromSlice(slice []*Snake, s *Snake) {
-	// return append(s[:index], s[index+1:]...)
-	newSnakes := make([]*Snake, 0)
-	for _, v := range slice {
-		if v != s {
-			// if v.SnakeParts[0] != s.SnakeParts[0] {
-			newSnakes = append(newSnakes, v)
+// collision tells what a snake would hit with its next move, it is empty if
+// the move is safe.
+func (g *Game) collision(i int) string {
+	nextHeadPosition, err := g.Snakes[i].nextHeadPosition()
+	if err != nil {
+		return err.Error()
+	}
+	if owner, ok := g.occupied.Owner(nextHeadPosition); ok {
+		if owner == i {
+			return CauseSelf
 		}
+		return fmt.Sprintf("snake P%v", owner+1)
 	}
+	if nextHeadPosition.x <= 0 || nextHeadPosition.y <= 0 || nextHeadPosition.x >= g.Board.width || nextHeadPosition.y >= g.Board.height {
+		return CauseWall
+	}
+	return ""
 }
 
 func (g *Game) Pause() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
 	if g.IsPaused {
 		g.IsPaused = false
 	} else {
@@ -381,105 +376,9 @@ func (g *Game) Pause() {
 	}
 }
 
-func (g *Game) botControl(snake *Snake, botChan chan int, runBotCalcChan1 chan bool, snakeNumber int) {
-	for {
-		if <-runBotCalcChan1 {
-			// startTime := time.Now().UnixMilli()
-			botSnake := g.Snakes[snakeNumber]
-
-			headCordinate := botSnake.SnakeParts[0].Coordinate
-			foodCordinate := g.Food[len(g.Food)-1].Coordinates
-
-			g.TestFields[snakeNumber] = fmt.Sprintf("%v Head Pos: (%v,%v) - Food (%v,%v)", snakeNumber, headCordinate.x, headCordinate.y, g.Food[len(g.Food)-1].Coordinates.x, g.Food[0].Coordinates.y)
-
-			world := ParseSnakeWorld(g, botSnake)
-
-			p, _, _ := Path(world.From(), world.To(), g)
-			g.BotPaths[snakeNumber] = world.getPathCoordinates(p)
-			// g.TestField4 = fmt.Sprintf("dist: %v, found: %v", dist, found)
-			var nextstep int
-			if len(world.getPathCoordinates(p)) >= 2 {
-				nextCoor := world.getPathCoordinates(p)[len(world.getPathCoordinates(p))-2]
-				nextstep = g.calculateDirection2(headCordinate, nextCoor, botSnake)
-			} else {
-				nextstep = g.calculateDirection2(headCordinate, foodCordinate, botSnake)
-			}
-
-			botChan <- nextstep
-		}
-	}
-}
-
-func (g *Game) calculateDirection(currentHeadPosition Coordinate, foodPosition Coordinate, snake *Snake) int {
-	var difference Coordinate
-	difference.x = currentHeadPosition.x - foodPosition.x
-	difference.y = currentHeadPosition.y - foodPosition.y
-
-	var dir int
-	for {
-		//2
-		if difference.x < 0 {
-			dir = Right
-			//1
-		} else if difference.x > 0 {
-			//3
-		} else if difference.y < 0 {
-			dir = Down
-
-			//0
-		} else if difference.y > 0 {
-			dir = Up
-		} else {
-			return -5
-		}
-		cm := snake.canMoveBot(g.Board, g.Snakes, dir)
-		if cm {
-			break
-		}
-	}
-	return dir
-}
-func (g *Game) calculateDirection2(currentHeadPosition Coordinate, goalCoordinate Coordinate, snake *Snake) int {
-	var difference Coordinate
-	difference.x = currentHeadPosition.x - goalCoordinate.x
-	difference.y = currentHeadPosition.y - goalCoordinate.y
-
-	var dir int = 0
-	var right bool = false
-	var left bool = false
-	var up bool = false
-	var down bool = false
-	for i := 0; i < 4; i++ {
-		//2
-		if (difference.x < 0 || left) && !right {
-			dir = Right
-			right = true
-			//1
-		} else if (difference.x > 0 || right) && !left {
-			dir = Left
-			left = true
-			//3
-		} else if (difference.y < 0 || up) && !down {
-			dir = Down
-			down = true
-			//0
-		} else if (difference.y > 0 || down) && !up {
-			dir = Up
-			up = true
-		} else {
-			return -5
-		}
-		cm := snake.canMoveBot(g.Board, g.Snakes, dir)
-		if cm {
-			break
-		}
-	}
-	return dir
-}
-
 // -----Display------------------------------------------------------------------------------
 // Display the game board.
-func (g *Game) drawBoard() {
+func (g *Game) drawBoard(s *Snapshot) {
 	width, height := g.Board.width, g.Board.height
 
 	boardStyle := tcell.StyleDefault.Background(tcell.ColorBlack).Foreground(tcell.ColorWhite)
@@ -510,8 +409,8 @@ func (g *Game) drawBoard() {
 	// g.drawText(1, height+2, width, height+10, fmt.Sprintf("P2 Score:%d", g.Snakes[1].Score))
 	textHeight := height + 1
 	score := ""
-	for i := 0; i < len(g.Snakes); i++ {
-		score += fmt.Sprintf("P%v score %v - ", i+1, g.Snakes[i].Score)
+	for i := 0; i < len(s.Snakes); i++ {
+		score += fmt.Sprintf("P%v score %v - ", i+1, s.Snakes[i].Score)
 	}
 	// g.drawText(1, textHeight, width, height+10, fmt.Sprintf("P1 Score:%d", g.Snakes[i].Score))
 	g.drawText(1, textHeight, fullWidth, fullHeight, fmt.Sprintf("%v", score))
@@ -520,21 +419,6 @@ func (g *Game) drawBoard() {
 	// textHeight++
 	// g.drawText(1, textHeight, width, height+10, "Press arrow keys to control direction")
 	// textHeight++
-
-	for _, v := range g.TestFields {
-		g.drawText(1, textHeight, fullWidth, fullHeight, v)
-		textHeight++
-	}
-
-	textHeight++
-	for key, element := range g.BotPaths {
-		g.drawText(1, textHeight, fullWidth-5, fullHeight, fmt.Sprintf("%v Snake path: %v", key, element))
-		textHeight++
-		textHeight++
-		textHeight++
-		g.drawText(1, textHeight, fullWidth, fullHeight, fmt.Sprintf("---"))
-		textHeight++
-	}
 }
 
 // Display text in terminal.
@@ -555,15 +439,9 @@ func (g *Game) drawText(x1, y1, x2, y2 int, text string) {
 	}
 }
 
-func (g *Game) drawSnake() {
-	for j, currentSnake := range g.Snakes {
-		var a tcell.Color
-		if !currentSnake.IsBot {
-			a = tcell.Color(tcell.ColorNames[g.settings.PlayersControlSettings[j].Color])
-		} else {
-			a = tcell.Color((j + 2) * 10)
-		}
-		snakeStyle := tcell.StyleDefault.Background(a)
+func (g *Game) drawSnake(s *Snapshot) {
+	for j, currentSnake := range s.Snakes {
+		snakeStyle := tcell.StyleDefault.Background(g.snakeColor(s, j))
 		for i, part := range currentSnake.SnakeParts {
 			if i == 0 {
 				g.Screen.SetContent(part.Coordinate.x, part.Coordinate.y, []rune(part.Letter)[0], nil, snakeStyle) //tcell.RuneBullet
@@ -574,42 +452,85 @@ func (g *Game) drawSnake() {
 	}
 }
 
-func (g *Game) drawLoading() {
-	if !g.hasStarted() {
+// Get the color of the j-th snake.
+func (g *Game) snakeColor(s *Snapshot, j int) tcell.Color {
+	if !s.Snakes[j].IsBot {
+		return tcell.Color(tcell.ColorNames[g.settings.PlayersControlSettings[j].Color])
+	}
+	return tcell.Color((j + 2) * 10)
+}
+
+func (g *Game) drawLoading(s *Snapshot) {
+	if !s.Started {
 		g.drawText(g.Board.width/2-12, g.Board.height/2, g.Board.width/2+13, g.Board.height/2, fmt.Sprintf("Press <ENTER> To Continue"))
+		g.drawHighScores(s)
 	}
 }
 
-func (g *Game) drawFood() {
+func (g *Game) drawFood(s *Snapshot) {
 	style := tcell.StyleDefault.Background(tcell.ColorBlack).Foreground(tcell.ColorRed)
-	for _, food := range g.Food {
-		g.Screen.SetContent(food.Coordinates.x, food.Coordinates.y, []rune(food.Letter)[0], nil, style)
+	for _, food := range s.Food {
+		_, _, current, _ := g.Screen.GetContent(food.Coordinates.x, food.Coordinates.y)
+		g.Screen.SetContent(food.Coordinates.x, food.Coordinates.y, []rune(food.Letter)[0], nil, style.Background(backgroundOf(current)))
 	}
 
 }
 
-func (g *Game) drawEnding() {
-	if g.hasEnded() && g.hasStarted() {
-		g.drawText(g.Board.width/2-5, g.Board.height/2-1, g.Board.width/2+10, g.Board.height/2, fmt.Sprintf("Game over P%v lost", g.whoLost+1))
-		g.drawText(g.Board.width/2-5, g.Board.height/2, g.Board.width/2+10, g.Board.height/2, "New Game? y/n")
+func (g *Game) drawEnding(s *Snapshot) {
+	if s.Over && s.Started {
+		g.drawText(g.Board.width/2-5, g.Board.height/2-1, g.Board.width/2+10, g.Board.height/2, fmt.Sprintf("Game over P%v lost", s.Lost+1))
+		if s.NameEntry >= 0 {
+			g.drawText(g.Board.width/2-10, g.Board.height/2, g.Board.width/2+20, g.Board.height/2, fmt.Sprintf("New high score P%v!", s.NameEntry+1))
+			g.drawText(g.Board.width/2-10, g.Board.height/2+1, g.Board.width/2+20, g.Board.height/2+1, fmt.Sprintf("Name: %v_", s.NameInput))
+		} else {
+			g.drawText(g.Board.width/2-5, g.Board.height/2, g.Board.width/2+10, g.Board.height/2, "New Game? y/n")
+		}
+		g.drawHighScores(s)
+		if status := s.StatsStatus; status != "" {
+			fullWidth, _ := g.Screen.Size()
+			y := highScoreTableSize + 4
+			g.drawText(g.Board.width+3, y, fullWidth, y+2, status)
+		}
+	}
+}
+
+// Display the high score table next to the board.
+func (g *Game) drawHighScores(s *Snapshot) {
+	fullWidth, _ := g.Screen.Size()
+	x, y := g.Board.width+3, 1
+	scores, status := s.HighScores, s.HighScoreStatus
+	g.drawText(x, y, fullWidth, y, "HIGH SCORES")
+	y++
+	for i, score := range scores {
+		g.drawText(x, y, fullWidth, y, fmt.Sprintf("%2d. %-12s %5d", i+1, score.Name, score.Score))
+		y++
+	}
+	if status != "" {
+		g.drawText(x, y+1, fullWidth, y+1, status)
 	}
 }
 
-func (g *Game) drawPause() {
-	if g.isPaused() {
+func (g *Game) drawPause(s *Snapshot) {
+	if s.Paused {
 		g.drawText(g.Board.width/2-5, g.Board.height/2, g.Board.width/2+10, g.Board.height/2, "PAUSED")
 	}
 }
 
+// updateScreen draws the current snapshot of the game.
 func (g *Game) updateScreen() {
+	s := g.Snapshot()
 	g.Screen.Clear()
-	g.drawBoard()
-	g.drawSnake()
-	g.drawFood()
-
-	g.drawLoading()
-	g.drawEnding()
-	g.drawPause()
+	g.drawBoard(s)
+	g.drawTerrain()
+	g.drawHeatmap(s)
+	g.drawBotPaths(s)
+	g.drawSnake(s)
+	g.drawFood(s)
+
+	g.drawLoading(s)
+	g.drawEnding(s)
+	g.drawPause(s)
+	g.drawDebug(s)
 
 	g.Screen.Show()
 }
@@ -622,14 +543,25 @@ func (g *Game) resizeScreen() {
 	g.mu.Unlock()
 }
 
+// exit stops the game, RunGame then shuts it down. A headless game only stops
+// its engine.
 func (g *Game) exit() {
-	g.mu.Lock()
-	g.Screen.Fini()
-	g.mu.Unlock()
-	os.Exit(0)
+	g.event("game quit")
+	if g.cancel != nil {
+		g.cancel()
+	}
+}
+
+// closeLog closes the log file of the game.
+func (g *Game) closeLog() {
+	if g.logFile != nil {
+		g.logger = nil
+		g.logFile.Close()
+	}
 }
 
 func (g *Game) start() {
+	g.event("game started", "mode", g.highScoreMode)
 	g.mu.Lock()
 	defer g.mu.Unlock()
 	g.IsStart = true
@@ -638,11 +570,19 @@ func (g *Game) start() {
 }
 
 func (g *Game) reStart() {
+	g.event("game restarted")
 	g.mu.Lock()
 	defer g.mu.Unlock()
 	g.IsStart = false
 	g.IsOver = false
 	g.reCreateSnakes()
+	g.buildOccupancy()
+	g.decisions.clear()
+	if g.bots != nil {
+		g.bots.reset()
+	}
+	g.stats = newGameStats(g.highScoreMode, g.Snakes)
+	g.statsStatus = ""
 }
 
 func (g *Game) hasStarted() bool {
@@ -656,12 +596,6 @@ func (g *Game) hasEnded() bool {
 	return g.IsOver
 }
 
-func (g *Game) isPaused() bool {
-	g.mu.Lock()
-	defer g.mu.Unlock()
-	return g.IsPaused
-}
-
 func (g *Game) shouldContinue() bool {
 	g.mu.Lock()
 	defer g.mu.Unlock()
@@ -671,6 +605,9 @@ func (g *Game) shouldContinue() bool {
 func (g *Game) over(i int) {
 	g.mu.Lock()
 	defer g.mu.Unlock()
+	if !g.IsOver {
+		g.queueHighScores()
+	}
 	g.IsOver = true
 	g.whoLost = i
 }
@@ -695,6 +632,7 @@ func (g *Game) shouldUpdateDirection(currentDirection int, direction int) bool {
 }
 
 func (g *Game) removeFood(food Food) {
+	g.occupied.removeFood(food.Coordinates)
 	newFoodList := make([]Food, 0)
 	for _, value := range g.Food {
 		b := value.Coordinates.x == food.Coordinates.x && value.Coordinates.y == food.Coordinates.y
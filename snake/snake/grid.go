@@ -0,0 +1,196 @@
+package snake
+
+import "snake2/astar"
+
+// Grid is a flat representation of a board for pathfinding. Cells are stored
+// row by row and indexed with y*Width+x, the border of the board is part of
+// the grid as blocker cells.
+type Grid struct {
+	Width, Height int
+	Kinds         []int
+	// Frees is the move in which a blocker cell of a snake body becomes free
+	// to enter, counted from the next move as 1. It is 0 for blockers that
+	// stay, and nil if the grid has no moving bodies. Only searches that
+	// look ahead in time use it, the others take every blocker as a wall.
+	Frees []int
+}
+
+// NewGrid creates a grid of plain tiles.
+func NewGrid(width, height int) *Grid {
+	return &Grid{
+		Width:  width,
+		Height: height,
+		Kinds:  make([]int, width*height),
+	}
+}
+
+// newBoardGrid creates a grid that covers a board and its walls.
+func newBoardGrid(board *Board) *Grid {
+	return NewGrid(board.width+1, board.height+1)
+}
+
+// Index returns the cell index of a coordinate.
+func (gr *Grid) Index(c Coordinate) int {
+	return c.y*gr.Width + c.x
+}
+
+// Coordinate returns the coordinate of a cell index.
+func (gr *Grid) Coordinate(i int) Coordinate {
+	return newCoordinate(i%gr.Width, i/gr.Width)
+}
+
+// Inside tells if a coordinate is on the grid.
+func (gr *Grid) Inside(c Coordinate) bool {
+	return c.x >= 0 && c.y >= 0 && c.x < gr.Width && c.y < gr.Height
+}
+
+// Walkable tells if a cell can be entered.
+func (gr *Grid) Walkable(i int) bool {
+	return gr.Kinds[i] != KindBlocker
+}
+
+// Kind returns the tile kind at a coordinate, cells off the grid are blockers.
+func (gr *Grid) Kind(c Coordinate) int {
+	if !gr.Inside(c) {
+		return KindBlocker
+	}
+	return gr.Kinds[gr.Index(c)]
+}
+
+// Set sets the tile kind at a coordinate.
+func (gr *Grid) Set(c Coordinate, kind int) {
+	if gr.Inside(c) {
+		gr.Kinds[gr.Index(c)] = kind
+	}
+}
+
+// Reset makes every border cell a blocker and every other cell plain.
+func (gr *Grid) Reset() {
+	for i := range gr.Kinds {
+		x, y := i%gr.Width, i/gr.Width
+		if x == 0 || y == 0 || x == gr.Width-1 || y == gr.Height-1 {
+			gr.Kinds[i] = KindBlocker
+		} else {
+			gr.Kinds[i] = KindPlain
+		}
+	}
+}
+
+// Graph returns the graph of the walkable cells of the grid for the astar
+// package, with the movement costs of KindCosts and the Manhattan distance as
+// heuristic. It reads the grid during the search, so the grid can be refilled
+// between searches.
+func (gr *Grid) Graph() astar.Graph[Coordinate] {
+	return astar.Graph[Coordinate]{
+		Neighbors: func(c Coordinate, buf []Coordinate) []Coordinate {
+			for _, dir := range []int{Up, Left, Right, Down} {
+				if n := c.step(dir); gr.Kind(n) != KindBlocker {
+					buf = append(buf, n)
+				}
+			}
+			return buf
+		},
+		Cost: func(from, to Coordinate) float64 {
+			if cost := KindCosts[gr.Kind(to)]; cost > 0 {
+				return cost
+			}
+			return 1
+		},
+		Heuristic: func(c, goal Coordinate) float64 {
+			return float64(manhattan(c, goal))
+		},
+	}
+}
+
+// World converts the grid into a World of Tiles for the Pather based search.
+func (gr *Grid) World() World {
+	w := World{}
+	for i, kind := range gr.Kinds {
+		c := gr.Coordinate(i)
+		w.SetTile(&Tile{
+			Kind: kind,
+		}, c.x, c.y)
+	}
+	return w
+}
+
+// botGrid draws a snapshot into a grid the way all bots see it: the terrain is
+// kept, food is a goal and every snake part is a blocker. Snakes hidden in
+// grass are left out. It is built once per tick and shared by the bots,
+// fillGrid adds what differs per bot.
+func (s *Snapshot) botGrid() *Grid {
+	gr := newBoardGrid(s.Board)
+	gr.Reset()
+	s.Board.fillTerrain(gr)
+	for _, f := range s.Food {
+		gr.Set(f.Coordinates, KindTo)
+	}
+	for _, snake := range s.Snakes {
+		if s.Board.Terrain(snake.SnakeParts[0].Coordinate) == KindGrass {
+			continue
+		}
+		for _, sp := range snake.SnakeParts {
+			gr.Set(sp.Coordinate, KindBlocker)
+		}
+	}
+	return gr
+}
+
+// fillGrid draws the board as seen by a bot into a grid: the bot grid of the
+// tick with the bot head as the start. A bot hidden in grass still sees its
+// own body. Must be called on a bot view.
+func (g *Game) fillGrid(gr *Grid, botSnake *Snake) {
+	copy(gr.Kinds, g.botGrid.Kinds)
+	hidden := g.hidden(botSnake)
+	for j, sp := range botSnake.SnakeParts {
+		if j == 0 {
+			gr.Set(sp.Coordinate, KindFrom)
+		} else if hidden {
+			gr.Set(sp.Coordinate, KindBlocker)
+		}
+	}
+	g.fillFrees(gr, botSnake)
+}
+
+// fillFrees sets when the body cells of the grid of a bot become free. A part
+// k places before the end of a snake, counting the parts it still grows by,
+// moves away after k+1 moves of the snake. The snakes before the bot move
+// first in a tick, so their cells are free for the bot in that move already,
+// its own cells and the ones of the snakes after it only in the next move.
+// A snake stuck in mud keeps its body for the ticks it sits out, and while
+// the bot sits out its own the other snakes move on without it.
+func (g *Game) fillFrees(gr *Grid, botSnake *Snake) {
+	if len(gr.Frees) != len(gr.Kinds) {
+		gr.Frees = make([]int, len(gr.Kinds))
+	}
+	for i := range gr.Frees {
+		gr.Frees[i] = 0
+	}
+	later := false
+	for _, s := range g.Snakes {
+		if s == botSnake {
+			later = true
+		} else if g.hidden(s) {
+			continue
+		}
+		length := len(s.SnakeParts)
+		for j, sp := range s.SnakeParts {
+			c := sp.Coordinate
+			if c == (Coordinate{}) || gr.Kind(c) != KindBlocker {
+				// parts still to grow and the head of the bot
+				continue
+			}
+			free := length - j
+			if later {
+				free++
+			}
+			if s != botSnake {
+				free += s.skip - botSnake.skip
+				if free < 1 {
+					free = 1
+				}
+			}
+			gr.Frees[gr.Index(c)] = free
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package snake
+
+// GridPather is an A* search specialised for Grids. Nodes live in a flat
+// array indexed by cell and are reused between searches, so a search on a
+// board of the same size does not allocate.
+type GridPather struct {
+	nodes []Node
+	// stamp tells in which search a node was last touched, nodes with an
+	// old stamp are treated as fresh without clearing the whole array.
+	stamp []uint32
+	gen   uint32
+	open  openHeap
+	path  []Coordinate
+	costs []int
+	// dijkstra turns the heuristic off, to search like Dijkstra.
+	dijkstra bool
+}
+
+// openCell is a cell in the open heap with the costs it was queued with. A
+// cell is queued again when it is reached cheaper, the old entry then stays
+// in the heap with its old costs and is skipped when it comes out.
+type openCell struct {
+	i     int32
+	fCost int
+	hCost int
+}
+
+// NewGridPather creates a grid pather with the movement costs of KindCosts.
+func NewGridPather() *GridPather {
+	maxKind := 0
+	for kind := range KindCosts {
+		if kind > maxKind {
+			maxKind = kind
+		}
+	}
+	costs := make([]int, maxKind+1)
+	for kind, cost := range KindCosts {
+		costs[kind] = int(cost)
+	}
+	return &GridPather{costs: costs}
+}
+
+// NewGridDijkstra creates a grid pather that searches like Dijkstra, without
+// the distance to the goal as heuristic.
+func NewGridDijkstra() *GridPather {
+	p := NewGridPather()
+	p.dijkstra = true
+	return p
+}
+
+// heuristic estimates the cost between two cells.
+func (p *GridPather) heuristic(from, to Coordinate) int {
+	if p.dijkstra {
+		return 0
+	}
+	return manhattan(from, to)
+}
+
+// cost returns the cost of entering a tile kind.
+func (p *GridPather) cost(kind int) int {
+	if kind < len(p.costs) && p.costs[kind] > 0 {
+		return p.costs[kind]
+	}
+	return 1
+}
+
+// prepare sizes the buffers for a grid and starts a new search generation.
+func (p *GridPather) prepare(gr *Grid) {
+	size := gr.Width * gr.Height
+	if len(p.nodes) < size {
+		p.nodes = make([]Node, size)
+		p.stamp = make([]uint32, size)
+		p.gen = 0
+	}
+	p.gen++
+	if p.gen == 0 {
+		for i := range p.stamp {
+			p.stamp[i] = 0
+		}
+		p.gen = 1
+	}
+	p.open = p.open[:0]
+}
+
+// node returns the node of a cell, resetting it if it is from an old search.
+func (p *GridPather) node(gr *Grid, i int) *Node {
+	n := &p.nodes[i]
+	if p.stamp[i] != p.gen {
+		p.stamp[i] = p.gen
+		*n = Node{
+			coordinates: gr.Coordinate(i),
+			isWalkable:  gr.Walkable(i),
+			direction:   -1,
+			gCost:       -1,
+		}
+	}
+	return n
+}
+
+// Path calculates a shortest path between two coordinates of the grid. The
+// path is in forward order, from first, and is only valid until the next call
+// of Path.
+//
+// If no path is found, found will be false.
+func (p *GridPather) Path(gr *Grid, from, to Coordinate) (path []Coordinate, distance int, found bool) {
+	if !gr.Inside(from) || !gr.Inside(to) {
+		return nil, 0, false
+	}
+	p.prepare(gr)
+	start := p.node(gr, gr.Index(from))
+	start.gCost = 0
+	start.hCost = p.heuristic(from, to)
+	start.calcFCost()
+	p.push(int32(gr.Index(from)))
+
+	goal := gr.Index(to)
+	for len(p.open) > 0 {
+		e := p.open.pop()
+		i := int(e.i)
+		current := &p.nodes[i]
+		if current.isClosed || e.fCost > current.fCost {
+			// A stale entry of a node that was reached cheaper later.
+			continue
+		}
+		current.Close()
+
+		if i == goal {
+			return p.reconstruct(current), current.gCost, true
+		}
+
+		x, y := i%gr.Width, i/gr.Width
+		for _, offset := range [4][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+			nx, ny := x+offset[0], y+offset[1]
+			if nx < 0 || ny < 0 || nx >= gr.Width || ny >= gr.Height {
+				continue
+			}
+			j := ny*gr.Width + nx
+			neighbor := p.node(gr, j)
+			if !neighbor.isWalkable || neighbor.isClosed {
+				continue
+			}
+			cost := current.gCost + p.cost(gr.Kinds[j])
+			if neighbor.gCost >= 0 && cost >= neighbor.gCost {
+				continue
+			}
+			neighbor.parent = current
+			neighbor.hCost = p.heuristic(neighbor.coordinates, to)
+			neighbor.SetgCost(cost)
+			p.push(int32(j))
+		}
+	}
+	return nil, 0, false
+}
+
+// reconstruct follows the parents of the goal node back to the start.
+func (p *GridPather) reconstruct(goal *Node) []Coordinate {
+	n := 0
+	for curr := goal; curr != nil; curr = curr.parent {
+		n++
+	}
+	if cap(p.path) < n {
+		p.path = make([]Coordinate, n)
+	}
+	p.path = p.path[:n]
+	for curr := goal; curr != nil; curr = curr.parent {
+		n--
+		p.path[n] = curr.coordinates
+	}
+	return p.path
+}
+
+// openHeap is a binary heap of open cells, ordered by f cost and preferring
+// cells closer to the goal.
+type openHeap []openCell
+
+func (h openHeap) less(a, b int) bool {
+	if h[a].fCost != h[b].fCost {
+		return h[a].fCost < h[b].fCost
+	}
+	return h[a].hCost < h[b].hCost
+}
+
+// push adds a cell to the heap.
+func (h *openHeap) push(e openCell) {
+	*h = append(*h, e)
+	open := *h
+	child := len(open) - 1
+	for child > 0 {
+		parent := (child - 1) / 2
+		if !open.less(child, parent) {
+			break
+		}
+		open[child], open[parent] = open[parent], open[child]
+		child = parent
+	}
+}
+
+// pop removes the best cell from the heap.
+func (h *openHeap) pop() openCell {
+	open := *h
+	top := open[0]
+	last := len(open) - 1
+	open[0] = open[last]
+	open = open[:last]
+	*h = open
+	parent := 0
+	for {
+		child := 2*parent + 1
+		if child >= last {
+			break
+		}
+		if child+1 < last && open.less(child+1, child) {
+			child++
+		}
+		if !open.less(child, parent) {
+			break
+		}
+		open[child], open[parent] = open[parent], open[child]
+		parent = child
+	}
+	return top
+}
+
+// push adds a cell to the open heap with its current costs.
+func (p *GridPather) push(i int32) {
+	n := &p.nodes[i]
+	p.open.push(openCell{i: i, fCost: n.fCost, hCost: n.hCost})
+}
+
+// manhattan is the orthogonal distance of two coordinates.
+func manhattan(a, b Coordinate) int {
+	dx := a.x - b.x
+	if dx < 0 {
+		dx = -dx
+	}
+	dy := a.y - b.y
+	if dy < 0 {
+		dy = -dy
+	}
+	return dx + dy
+}
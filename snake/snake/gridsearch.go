@@ -0,0 +1,472 @@
+package snake
+
+import (
+	"sort"
+
+	"snake2/astar"
+)
+
+// A GridSearch finds paths between cells of a Grid. The path is in forward
+// order, from first, and is only valid until the next call of Path. The
+// distance is the sum of the movement costs of the entered cells, or the
+// number of steps for searches that ignore the costs.
+type GridSearch interface {
+	Path(gr *Grid, from, to Coordinate) (path []Coordinate, distance int, found bool)
+	// Trace returns the cells touched by the last search.
+	Trace() SearchTrace
+}
+
+// Search algorithms of the bot settings.
+const (
+	SearchAStar         = "astar"
+	SearchGeneric       = "generic"
+	SearchBFS           = "bfs"
+	SearchDijkstra      = "dijkstra"
+	SearchJPS           = "jps"
+	SearchBidirectional = "bidirectional"
+	SearchDStar         = "dstar"
+	SearchSpaceTime     = "spacetime"
+)
+
+// GridSearches create the search algorithms by name.
+var GridSearches = map[string]func() GridSearch{
+	SearchAStar:         func() GridSearch { return NewGridPather() },
+	SearchGeneric:       func() GridSearch { return &genericSearch{} },
+	SearchBFS:           func() GridSearch { return &bfsSearch{} },
+	SearchDijkstra:      func() GridSearch { return NewGridDijkstra() },
+	SearchJPS:           func() GridSearch { return &jpsSearch{} },
+	SearchBidirectional: func() GridSearch { return &bidirectionalSearch{} },
+	SearchDStar:         func() GridSearch { return newDStarSearch() },
+	SearchSpaceTime:     func() GridSearch { return newSpaceTimeSearch() },
+}
+
+// SearchNames returns the names of the search algorithms in order.
+func SearchNames() []string {
+	names := make([]string, 0, len(GridSearches))
+	for name := range GridSearches {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// newGridSearch creates the search algorithm of a name, or the fallback one if
+// the name is empty or unknown.
+func (g *Game) newGridSearch(name string, fallback string) GridSearch {
+	if name == "" {
+		name = fallback
+	}
+	create, ok := GridSearches[name]
+	if !ok {
+		g.logger.Warn("unknown search algorithm", "search", name, "using", fallback)
+		create = GridSearches[fallback]
+	}
+	return create()
+}
+
+// cellStamps marks cells as seen in a search without clearing them between
+// searches: a cell is seen if its stamp is the one of the current search.
+type cellStamps struct {
+	stamp []uint32
+	gen   uint32
+}
+
+// next starts a new search on a grid of a size.
+func (s *cellStamps) next(size int) {
+	if len(s.stamp) < size {
+		s.stamp = make([]uint32, size)
+		s.gen = 0
+	}
+	s.gen++
+	if s.gen == 0 {
+		for i := range s.stamp {
+			s.stamp[i] = 0
+		}
+		s.gen = 1
+	}
+}
+
+func (s *cellStamps) seen(i int) bool {
+	return s.stamp[i] == s.gen
+}
+
+func (s *cellStamps) mark(i int) {
+	s.stamp[i] = s.gen
+}
+
+// cells returns the coordinates of the cells seen in the current search.
+func (s *cellStamps) cells(gr *Grid) []Coordinate {
+	cells := make([]Coordinate, 0)
+	for i := 0; i < len(s.stamp) && i < len(gr.Kinds); i++ {
+		if s.seen(i) {
+			cells = append(cells, gr.Coordinate(i))
+		}
+	}
+	return cells
+}
+
+// pathTo follows the parents of a cell back to the start and returns the path
+// in forward order in buf.
+func pathTo(gr *Grid, parent []int32, goal int, buf []Coordinate) []Coordinate {
+	buf = buf[:0]
+	for i := int32(goal); i != -1; i = parent[i] {
+		buf = append(buf, gr.Coordinate(int(i)))
+	}
+	for i, j := 0, len(buf)-1; i < j; i, j = i+1, j-1 {
+		buf[i], buf[j] = buf[j], buf[i]
+	}
+	return buf
+}
+
+// neighborCell returns the index of a neighbor of a cell in a direction,
+// false if it is off the grid.
+func neighborCell(gr *Grid, i int, dir int) (int, bool) {
+	c := gr.Coordinate(i).step(dir)
+	if !gr.Inside(c) {
+		return 0, false
+	}
+	return gr.Index(c), true
+}
+
+// genericSearch runs the generic astar package on the graph of a grid.
+type genericSearch struct {
+	reached cellStamps
+	closed  cellStamps
+	grid    *Grid
+}
+
+func (s *genericSearch) Path(gr *Grid, from, to Coordinate) ([]Coordinate, int, bool) {
+	s.grid = gr
+	s.reached.next(len(gr.Kinds))
+	s.closed.next(len(gr.Kinds))
+	graph := gr.Graph()
+	graph.Reached = func(c Coordinate) {
+		if gr.Inside(c) {
+			s.reached.mark(gr.Index(c))
+		}
+	}
+	graph.Expanded = func(c Coordinate) {
+		if gr.Inside(c) {
+			s.closed.mark(gr.Index(c))
+		}
+	}
+	path, distance, found := astar.Path(graph, from, to)
+	return path, int(distance), found
+}
+
+func (s *genericSearch) Trace() SearchTrace {
+	trace := SearchTrace{
+		Expanded: make([]Coordinate, 0),
+		Open:     make([]Coordinate, 0),
+	}
+	if s.grid == nil {
+		return trace
+	}
+	for i := 0; i < len(s.grid.Kinds); i++ {
+		if s.closed.seen(i) {
+			trace.Expanded = append(trace.Expanded, s.grid.Coordinate(i))
+		} else if s.reached.seen(i) {
+			trace.Open = append(trace.Open, s.grid.Coordinate(i))
+		}
+	}
+	return trace
+}
+
+// bfsSearch is a breadth-first search. It finds the path with the fewest
+// steps and ignores the movement costs.
+type bfsSearch struct {
+	seen   cellStamps
+	parent []int32
+	queue  []int32
+	path   []Coordinate
+	grid   *Grid
+}
+
+func (s *bfsSearch) Path(gr *Grid, from, to Coordinate) ([]Coordinate, int, bool) {
+	s.grid = gr
+	size := gr.Width * gr.Height
+	s.seen.next(size)
+	if len(s.parent) < size {
+		s.parent = make([]int32, size)
+	}
+	if !gr.Inside(from) || !gr.Inside(to) {
+		return nil, 0, false
+	}
+	start, goal := gr.Index(from), gr.Index(to)
+	s.seen.mark(start)
+	s.parent[start] = -1
+	s.queue = append(s.queue[:0], int32(start))
+	for head := 0; head < len(s.queue); head++ {
+		i := int(s.queue[head])
+		if i == goal {
+			s.path = pathTo(gr, s.parent, goal, s.path)
+			return s.path, len(s.path) - 1, true
+		}
+		for dir := Up; dir <= Down; dir++ {
+			j, ok := neighborCell(gr, i, dir)
+			if !ok || s.seen.seen(j) || !gr.Walkable(j) {
+				continue
+			}
+			s.seen.mark(j)
+			s.parent[j] = int32(i)
+			s.queue = append(s.queue, int32(j))
+		}
+	}
+	return nil, 0, false
+}
+
+func (s *bfsSearch) Trace() SearchTrace {
+	if s.grid == nil {
+		return SearchTrace{}
+	}
+	return SearchTrace{Expanded: s.seen.cells(s.grid)}
+}
+
+// jpsSearch is a Jump Point Search for grids with four neighbors. It runs the
+// astar package over jump points: from a cell it jumps straight ahead until
+// the goal, a cell next to the corner of an obstacle or, when it moves
+// horizontally, a cell from where a vertical jump finds one. It is fast on
+// open boards, but it treats every walkable cell as plain and ignores the
+// movement costs.
+type jpsSearch struct {
+	grid  *Grid
+	goal  Coordinate
+	graph astar.Graph[Coordinate]
+	path  []Coordinate
+	trace SearchTrace
+}
+
+func (s *jpsSearch) Path(gr *Grid, from, to Coordinate) ([]Coordinate, int, bool) {
+	s.grid, s.goal = gr, to
+	s.trace = SearchTrace{Expanded: make([]Coordinate, 0)}
+	if s.graph.Neighbors == nil {
+		s.graph = astar.Graph[Coordinate]{
+			Neighbors: s.successors,
+			Cost: func(from, to Coordinate) float64 {
+				return float64(manhattan(from, to))
+			},
+			Heuristic: func(c, goal Coordinate) float64 {
+				return float64(manhattan(c, goal))
+			},
+			Expanded: func(c Coordinate) {
+				s.trace.Expanded = append(s.trace.Expanded, c)
+			},
+		}
+	}
+	if !s.walkable(from.x, from.y) || !s.walkable(to.x, to.y) {
+		return nil, 0, false
+	}
+	points, distance, found := astar.Path(s.graph, from, to)
+	if !found {
+		return nil, 0, false
+	}
+	// fill the straight lines between the jump points
+	s.path = append(s.path[:0], points[0])
+	for _, p := range points[1:] {
+		c := s.path[len(s.path)-1]
+		dir := c.directionTo(newCoordinate(c.x+sign(p.x-c.x), c.y+sign(p.y-c.y)))
+		for c != p {
+			c = c.step(dir)
+			s.path = append(s.path, c)
+		}
+	}
+	return s.path, int(distance), true
+}
+
+func (s *jpsSearch) Trace() SearchTrace {
+	return s.trace
+}
+
+// successors appends the jump points reachable from a cell.
+func (s *jpsSearch) successors(c Coordinate, buf []Coordinate) []Coordinate {
+	for _, d := range [4][2]int{{0, -1}, {-1, 0}, {1, 0}, {0, 1}} {
+		if p, ok := s.jump(c, d[0], d[1]); ok {
+			buf = append(buf, p)
+		}
+	}
+	return buf
+}
+
+// jump moves from a cell in a direction until it finds a jump point.
+func (s *jpsSearch) jump(c Coordinate, dx, dy int) (Coordinate, bool) {
+	for {
+		n := newCoordinate(c.x+dx, c.y+dy)
+		if !s.walkable(n.x, n.y) {
+			return n, false
+		}
+		if n == s.goal {
+			return n, true
+		}
+		if dx != 0 {
+			if s.walkable(n.x, n.y-1) && !s.walkable(c.x, c.y-1) ||
+				s.walkable(n.x, n.y+1) && !s.walkable(c.x, c.y+1) {
+				return n, true
+			}
+			if _, ok := s.jump(n, 0, -1); ok {
+				return n, true
+			}
+			if _, ok := s.jump(n, 0, 1); ok {
+				return n, true
+			}
+		} else if s.walkable(n.x-1, n.y) && !s.walkable(c.x-1, c.y) ||
+			s.walkable(n.x+1, n.y) && !s.walkable(c.x+1, c.y) {
+			return n, true
+		}
+		c = n
+	}
+}
+
+func (s *jpsSearch) walkable(x, y int) bool {
+	return s.grid.Kind(newCoordinate(x, y)) != KindBlocker
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
+
+// bidirectionalSearch runs A* from both ends at once and stops when the
+// cheapest path through a cell both searches reached can't be beaten by
+// either of them anymore.
+type bidirectionalSearch struct {
+	forward, backward halfSearch
+	costs             []int
+	path              []Coordinate
+	grid              *Grid
+}
+
+// halfSearch is one direction of a bidirectional search.
+type halfSearch struct {
+	seen   cellStamps
+	closed cellStamps
+	g      []int
+	parent []int32
+	open   openHeap
+	// target is the cell the half searches toward, for the heuristic.
+	target Coordinate
+}
+
+func (h *halfSearch) prepare(size int, target Coordinate) {
+	h.seen.next(size)
+	h.closed.next(size)
+	if len(h.g) < size {
+		h.g = make([]int, size)
+		h.parent = make([]int32, size)
+	}
+	h.open = h.open[:0]
+	h.target = target
+}
+
+// reach records a cheaper way to a cell and queues it.
+func (h *halfSearch) reach(gr *Grid, i int, parent int32, g int) {
+	h.seen.mark(i)
+	h.g[i] = g
+	h.parent[i] = parent
+	hCost := manhattan(gr.Coordinate(i), h.target)
+	h.open.push(openCell{i: int32(i), fCost: g + hCost, hCost: hCost})
+}
+
+// minF drops the stale entries of the open heap and returns the lowest f cost,
+// false if the heap is empty.
+func (h *halfSearch) minF() (int, bool) {
+	for len(h.open) > 0 {
+		top := h.open[0]
+		i := int(top.i)
+		if !h.closed.seen(i) && top.fCost == h.g[i]+top.hCost {
+			return top.fCost, true
+		}
+		h.open.pop()
+	}
+	return 0, false
+}
+
+func (s *bidirectionalSearch) Path(gr *Grid, from, to Coordinate) ([]Coordinate, int, bool) {
+	s.grid = gr
+	if s.costs == nil {
+		s.costs = NewGridPather().costs
+	}
+	size := gr.Width * gr.Height
+	s.forward.prepare(size, to)
+	s.backward.prepare(size, from)
+	if !gr.Inside(from) || !gr.Inside(to) || !gr.Walkable(gr.Index(from)) || !gr.Walkable(gr.Index(to)) {
+		return nil, 0, false
+	}
+	start, goal := gr.Index(from), gr.Index(to)
+	if start == goal {
+		s.path = append(s.path[:0], from)
+		return s.path, 0, true
+	}
+	s.forward.reach(gr, start, -1, 0)
+	// the backward search counts the cost of the cells it leaves, as they are
+	// the cells the path enters
+	s.backward.reach(gr, goal, -1, 0)
+
+	best, meet := -1, -1
+	for {
+		fMin, fOk := s.forward.minF()
+		bMin, bOk := s.backward.minF()
+		if !fOk || !bOk {
+			break
+		}
+		if best >= 0 && (best <= fMin || best <= bMin) {
+			break
+		}
+		// expand the side with the smaller open heap
+		half, other, forward := &s.forward, &s.backward, true
+		if len(s.backward.open) < len(s.forward.open) {
+			half, other, forward = &s.backward, &s.forward, false
+		}
+		i := int(half.open.pop().i)
+		half.closed.mark(i)
+		for dir := Up; dir <= Down; dir++ {
+			j, ok := neighborCell(gr, i, dir)
+			if !ok || !gr.Walkable(j) || half.closed.seen(j) {
+				continue
+			}
+			step := s.cost(gr.Kinds[j])
+			if !forward {
+				step = s.cost(gr.Kinds[i])
+			}
+			g := half.g[i] + step
+			if half.seen.seen(j) && g >= half.g[j] {
+				continue
+			}
+			half.reach(gr, j, int32(i), g)
+			if other.seen.seen(j) {
+				if total := g + other.g[j]; best < 0 || total < best {
+					best, meet = total, j
+				}
+			}
+		}
+	}
+	if best < 0 {
+		return nil, 0, false
+	}
+
+	s.path = pathTo(gr, s.forward.parent, meet, s.path)
+	for i := s.backward.parent[meet]; i != -1; i = s.backward.parent[i] {
+		s.path = append(s.path, gr.Coordinate(int(i)))
+	}
+	return s.path, best, true
+}
+
+func (s *bidirectionalSearch) cost(kind int) int {
+	if kind < len(s.costs) && s.costs[kind] > 0 {
+		return s.costs[kind]
+	}
+	return 1
+}
+
+func (s *bidirectionalSearch) Trace() SearchTrace {
+	if s.grid == nil {
+		return SearchTrace{}
+	}
+	return SearchTrace{
+		Expanded: append(s.forward.closed.cells(s.grid), s.backward.closed.cells(s.grid)...),
+	}
+}
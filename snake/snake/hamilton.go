@@ -0,0 +1,205 @@
+package snake
+
+// hamiltonBot follows a Hamiltonian cycle over the board, so a lone snake
+// never runs into itself and can fill the whole board. It cuts across the
+// cycle towards the food only when the cut lands in the free part of the
+// cycle in front of it, which keeps its body in cycle order.
+//
+// An arena with two odd sides, like the 49x19 one of the default board, has
+// no Hamiltonian cycle. There the cycle leaves out the bottom left cell and
+// the bot only goes there for food right next to its head, so it can't fill
+// the board: once the snake needs that cell to keep moving it runs out of
+// moves.
+type hamiltonBot struct {
+	grid  *Grid
+	cycle []Coordinate
+	// order is the position of a grid cell in the cycle, -1 if the cell is
+	// not part of it.
+	order   []int
+	targets *foodTargets
+}
+
+// hamiltonMargin is the number of cycle cells a cut keeps free in front of
+// the tail, so growing after the cut can't close the gap.
+const hamiltonMargin = 4
+
+func newHamiltonBot(board *Board, targets *foodTargets) *hamiltonBot {
+	b := &hamiltonBot{
+		grid:    newBoardGrid(board),
+		cycle:   hamiltonianCycle(board.width-1, board.height-1),
+		targets: targets,
+	}
+	b.order = make([]int, len(b.grid.Kinds))
+	for i := range b.order {
+		b.order[i] = -1
+	}
+	for i := range b.cycle {
+		// the board starts at 1,1 inside the walls
+		b.cycle[i] = newCoordinate(b.cycle[i].x+1, b.cycle[i].y+1)
+		b.order[b.grid.Index(b.cycle[i])] = i
+	}
+	return b
+}
+
+func (b *hamiltonBot) NextDirection(g *Game, snakeNumber int) int {
+	g.fillGrid(b.grid, g.Snakes[snakeNumber])
+	body, _ := g.snakeBody(snakeNumber)
+	food, hasFood := b.targets.choose(g, snakeNumber, b.grid)
+	head := body[0]
+	if len(b.cycle) == 0 || b.position(head) < 0 {
+		return b.freeMove(head)
+	}
+
+	next := b.cycle[(b.position(head)+1)%len(b.cycle)]
+	if hasFood && b.position(food) >= 0 && len(body) < len(b.cycle)/2 {
+		maxCut := b.distance(head, b.tail(body)) - hamiltonMargin - (len(body) - b.placed(body))
+		foodDist := b.distance(head, food)
+		for _, dir := range []int{Up, Left, Right, Down} {
+			n := head.step(dir)
+			if b.position(n) < 0 || b.grid.Kind(n) == KindBlocker {
+				continue
+			}
+			d := b.distance(head, n)
+			if d <= maxCut && d <= foodDist && d > b.distance(head, next) {
+				next = n
+			}
+		}
+	}
+	if hasFood && b.position(food) < 0 && manhattan(head, food) == 1 {
+		// the cell left out of the cycle can be visited in place of the
+		// next cycle cell when it touches the one after it
+		after := b.cycle[(b.position(head)+2)%len(b.cycle)]
+		if manhattan(food, after) == 1 && b.grid.Kind(after) != KindBlocker {
+			next = food
+		}
+	}
+	if b.grid.Kind(next) == KindBlocker {
+		return b.freeMove(head)
+	}
+
+	if hasFood {
+		g.setBotPath(snakeNumber, b.cyclePath(next, food))
+	}
+	return head.directionTo(next)
+}
+
+// position returns the position of a coordinate in the cycle.
+func (b *hamiltonBot) position(c Coordinate) int {
+	if !b.grid.Inside(c) {
+		return -1
+	}
+	return b.order[b.grid.Index(c)]
+}
+
+// distance is the number of steps from a to b along the cycle.
+func (b *hamiltonBot) distance(from, to Coordinate) int {
+	n := len(b.cycle)
+	return (b.position(to) - b.position(from) + n) % n
+}
+
+// tail returns the last body part that is on the cycle, a part that was just
+// grown is not placed yet.
+func (b *hamiltonBot) tail(body []Coordinate) Coordinate {
+	for i := len(body) - 1; i > 0; i-- {
+		if b.position(body[i]) >= 0 {
+			return body[i]
+		}
+	}
+	return body[0]
+}
+
+// placed returns the number of body parts that are on the board.
+func (b *hamiltonBot) placed(body []Coordinate) int {
+	n := 0
+	for _, c := range body {
+		if c != (Coordinate{}) {
+			n++
+		}
+	}
+	return n
+}
+
+// freeMove gets the snake back onto the cycle when it is not on it or the
+// cycle is blocked: it takes the free neighbor that comes first in the cycle.
+func (b *hamiltonBot) freeMove(head Coordinate) int {
+	best, bestDist := -1, 0
+	for _, dir := range []int{Up, Left, Right, Down} {
+		n := head.step(dir)
+		if b.grid.Kind(n) == KindBlocker {
+			continue
+		}
+		d := len(b.cycle)
+		if b.position(n) >= 0 && b.position(head) >= 0 {
+			d = b.distance(head, n)
+		}
+		if best == -1 || d < bestDist {
+			best, bestDist = dir, d
+		}
+	}
+	return best
+}
+
+// cyclePath returns the cycle cells from one coordinate to another.
+func (b *hamiltonBot) cyclePath(from, to Coordinate) []Coordinate {
+	path := []Coordinate{from}
+	if b.position(from) < 0 {
+		return path
+	}
+	for i := b.position(from); b.cycle[i] != to && len(path) < len(b.cycle); {
+		i = (i + 1) % len(b.cycle)
+		path = append(path, b.cycle[i])
+	}
+	return path
+}
+
+// hamiltonianCycle builds a cycle over a width x height area starting at
+// 0,0. The rows are walked in a zigzag leaving out the first column, which
+// leads back to the start. If both sides are odd, the last row is woven into
+// the row above it in pairs of cells and its first cell is left out.
+func hamiltonianCycle(width, height int) []Coordinate {
+	if width < 2 || height < 2 {
+		return nil
+	}
+	if height%2 == 0 {
+		return zigzagCycle(width, height)
+	}
+	if width%2 == 0 {
+		cycle := zigzagCycle(height, width)
+		for i, c := range cycle {
+			cycle[i] = newCoordinate(c.y, c.x)
+		}
+		return cycle
+	}
+
+	cycle := make([]Coordinate, 0, width*height-1)
+	last := height - 1
+	for _, c := range zigzagCycle(width, height-1) {
+		cycle = append(cycle, c)
+		// the second to last row is walked from right to left, every
+		// step from an even column makes a detour through the last row
+		if c.y == last-1 && c.x > 0 && c.x%2 == 0 {
+			cycle = append(cycle, newCoordinate(c.x, last), newCoordinate(c.x-1, last))
+		}
+	}
+	return cycle
+}
+
+// zigzagCycle builds a cycle over an area with an even height.
+func zigzagCycle(width, height int) []Coordinate {
+	cycle := make([]Coordinate, 0, width*height)
+	for y := 0; y < height; y++ {
+		if y%2 == 0 {
+			for x := 1; x < width; x++ {
+				cycle = append(cycle, newCoordinate(x, y))
+			}
+		} else {
+			for x := width - 1; x >= 1; x-- {
+				cycle = append(cycle, newCoordinate(x, y))
+			}
+		}
+	}
+	for y := height - 1; y >= 0; y-- {
+		cycle = append(cycle, newCoordinate(0, y))
+	}
+	return cycle
+}
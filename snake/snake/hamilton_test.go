@@ -0,0 +1,44 @@
+package snake
+
+import "testing"
+
+func TestHamiltonianCycle(t *testing.T) {
+	tests := []struct {
+		width, height int
+		// full tells if the cycle goes through every cell, else it leaves
+		// out the bottom left one.
+		full bool
+	}{
+		{2, 2, true},
+		{4, 3, true},
+		{3, 4, true},
+		{6, 6, true},
+		{3, 3, false},
+		{5, 7, false},
+		{49, 19, false},
+	}
+	for _, tt := range tests {
+		cycle := hamiltonianCycle(tt.width, tt.height)
+		cells := tt.width * tt.height
+		if !tt.full {
+			cells--
+		}
+		if len(cycle) != cells {
+			t.Errorf("%vx%v: cycle has %v cells, want %v", tt.width, tt.height, len(cycle), cells)
+			continue
+		}
+		seen := map[Coordinate]bool{}
+		for i, c := range cycle {
+			if c.x < 0 || c.y < 0 || c.x >= tt.width || c.y >= tt.height || seen[c] {
+				t.Fatalf("%vx%v: cell %v is outside or repeated", tt.width, tt.height, c)
+			}
+			seen[c] = true
+			if next := cycle[(i+1)%len(cycle)]; manhattan(c, next) != 1 {
+				t.Fatalf("%vx%v: %v doesn't lead to %v", tt.width, tt.height, c, next)
+			}
+		}
+		if left := newCoordinate(0, tt.height-1); seen[left] != tt.full {
+			t.Errorf("%vx%v: bottom left cell in the cycle is %v", tt.width, tt.height, seen[left])
+		}
+	}
+}
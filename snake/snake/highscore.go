@@ -0,0 +1,240 @@
+package snake
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell"
+)
+
+const (
+	highScoreTableSize = 10
+	highScoreNameLimit = 12
+	highScoreVersion   = 1
+	appDirName         = "go-snake"
+)
+
+// HighScore is one entry of a high score table.
+type HighScore struct {
+	Name  string    `json:"name"`
+	Score int       `json:"score"`
+	Date  time.Time `json:"date"`
+}
+
+// highScoreFile is the on-disk format of a high score table.
+type highScoreFile struct {
+	Version int         `json:"version"`
+	Mode    string      `json:"mode"`
+	Scores  []HighScore `json:"scores"`
+}
+
+// dataDir returns the per user data directory of the game, following the XDG
+// base directory specification.
+func dataDir() (string, error) {
+	base := os.Getenv("XDG_DATA_HOME")
+	if base == "" || !filepath.IsAbs(base) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		base = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(base, appDirName), nil
+}
+
+// highScoreMode names the mode and board configuration a table belongs to.
+func highScoreMode(playerNumber, botNumber, foodNumber int, board *Board) string {
+	return fmt.Sprintf("p%d-b%d-f%d-%dx%d", playerNumber, botNumber, foodNumber, board.width, board.height)
+}
+
+// highScorePath returns the file that stores the table of a mode.
+func highScorePath(mode string) (string, error) {
+	dir, err := dataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "highscores-"+mode+".json"), nil
+}
+
+// loadHighScores reads a high score table. A missing file is an empty table.
+// A corrupted file is moved aside so the next save starts a fresh table
+// instead of failing forever.
+func loadHighScores(path string, mode string) ([]HighScore, error) {
+	data, err := ioutil.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var file highScoreFile
+	if err := json.Unmarshal(data, &file); err != nil || file.Version != highScoreVersion || file.Mode != mode {
+		os.Rename(path, path+".corrupt")
+		return nil, fmt.Errorf("high score file %s is corrupted, moved aside", path)
+	}
+	return normalizeHighScores(file.Scores), nil
+}
+
+// saveHighScores writes a high score table atomically: the table is written
+// to a temporary file in the same directory which then replaces the old one.
+func saveHighScores(path string, mode string, scores []HighScore) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(highScoreFile{
+		Version: highScoreVersion,
+		Mode:    mode,
+		Scores:  scores,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), ".highscores-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// normalizeHighScores drops invalid entries, sorts the table and cuts it to
+// its maximum size.
+func normalizeHighScores(scores []HighScore) []HighScore {
+	valid := make([]HighScore, 0, len(scores))
+	for _, s := range scores {
+		if s.Score <= 0 {
+			continue
+		}
+		s.Name = cleanHighScoreName(s.Name)
+		valid = append(valid, s)
+	}
+	sort.SliceStable(valid, func(i, j int) bool {
+		if valid[i].Score != valid[j].Score {
+			return valid[i].Score > valid[j].Score
+		}
+		return valid[i].Date.Before(valid[j].Date)
+	})
+	if len(valid) > highScoreTableSize {
+		valid = valid[:highScoreTableSize]
+	}
+	return valid
+}
+
+// cleanHighScoreName removes control characters and limits the name length.
+func cleanHighScoreName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r < ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if r := []rune(name); len(r) > highScoreNameLimit {
+		name = string(r[:highScoreNameLimit])
+	}
+	if name == "" {
+		name = "???"
+	}
+	return name
+}
+
+// qualifiesForHighScore tells if a score would make it into the table.
+func qualifiesForHighScore(scores []HighScore, score int) bool {
+	if score <= 0 {
+		return false
+	}
+	if len(scores) < highScoreTableSize {
+		return true
+	}
+	return score > scores[len(scores)-1].Score
+}
+
+// addHighScore inserts a new entry and returns the new table.
+func addHighScore(scores []HighScore, entry HighScore) []HighScore {
+	return normalizeHighScores(append(append([]HighScore{}, scores...), entry))
+}
+
+// initHighScores loads the table that belongs to the game configuration.
+func (g *Game) initHighScores() {
+	g.highScoreMode = highScoreMode(g.PlayerNumber, g.BotNumber, g.FoodNumber, g.Board)
+	path, err := highScorePath(g.highScoreMode)
+	if err != nil {
+		g.highScoreStatus = err.Error()
+		return
+	}
+	g.highScorePath = path
+	g.HighScores, err = loadHighScores(path, g.highScoreMode)
+	if err != nil {
+		g.logger.Warn("high scores not loaded", "err", err)
+		g.highScoreStatus = err.Error()
+	}
+}
+
+// queueHighScores collects the players whose score made it into the table,
+// they are asked for their name one after the other. Must hold g.mu.
+func (g *Game) queueHighScores() {
+	scores := g.HighScores
+	for i := 0; i < g.PlayerNumber && i < len(g.Snakes); i++ {
+		score := g.Snakes[i].Score
+		if qualifiesForHighScore(scores, score) {
+			g.nameEntries = append(g.nameEntries, i)
+			scores = addHighScore(scores, HighScore{Score: score, Date: time.Now()})
+		}
+	}
+	g.nameInput = ""
+}
+
+// handleNameInput edits the name of the current high score entry and stores
+// the entry when it is confirmed with enter.
+func (g *Game) handleNameInput(event *tcell.EventKey) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(g.nameEntries) == 0 {
+		return
+	}
+	switch event.Key() {
+	case tcell.KeyEnter:
+		player := g.nameEntries[0]
+		g.nameEntries = g.nameEntries[1:]
+		g.HighScores = addHighScore(g.HighScores, HighScore{
+			Name:  g.nameInput,
+			Score: g.Snakes[player].Score,
+			Date:  time.Now(),
+		})
+		g.nameInput = ""
+		if g.highScorePath == "" {
+			return
+		}
+		if err := saveHighScores(g.highScorePath, g.highScoreMode, g.HighScores); err != nil {
+			g.logger.Error("high scores not saved", "err", err)
+			g.highScoreStatus = err.Error()
+		}
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if r := []rune(g.nameInput); len(r) > 0 {
+			g.nameInput = string(r[:len(r)-1])
+		}
+	case tcell.KeyRune:
+		if len([]rune(g.nameInput)) < highScoreNameLimit {
+			g.nameInput += string(event.Rune())
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package snake
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LogLevel is the severity of a log record.
+type LogLevel int
+
+// Log levels, from the most to the least verbose.
+const (
+	LevelDebug LogLevel = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = map[LogLevel]string{
+	LevelDebug: "DEBUG",
+	LevelInfo:  "INFO",
+	LevelWarn:  "WARN",
+	LevelError: "ERROR",
+}
+
+// ParseLogLevel parses a level name, case insensitive. Unknown names are
+// LevelInfo.
+func ParseLogLevel(name string) LogLevel {
+	for level, levelName := range levelNames {
+		if strings.EqualFold(name, levelName) {
+			return level
+		}
+	}
+	return LevelInfo
+}
+
+// Logger writes leveled records as key=value pairs, one record per line. A
+// nil logger discards every record.
+type Logger struct {
+	mu    sync.Mutex
+	w     io.Writer
+	level LogLevel
+}
+
+// NewLogger creates a logger that writes the records of a level and above.
+func NewLogger(w io.Writer, level LogLevel) *Logger {
+	return &Logger{w: w, level: level}
+}
+
+// Debug logs a record at LevelDebug with key value pairs.
+func (l *Logger) Debug(msg string, keyValues ...interface{}) {
+	l.log(LevelDebug, msg, keyValues)
+}
+
+// Info logs a record at LevelInfo with key value pairs.
+func (l *Logger) Info(msg string, keyValues ...interface{}) {
+	l.log(LevelInfo, msg, keyValues)
+}
+
+// Warn logs a record at LevelWarn with key value pairs.
+func (l *Logger) Warn(msg string, keyValues ...interface{}) {
+	l.log(LevelWarn, msg, keyValues)
+}
+
+// Error logs a record at LevelError with key value pairs.
+func (l *Logger) Error(msg string, keyValues ...interface{}) {
+	l.log(LevelError, msg, keyValues)
+}
+
+// Enabled tells if records of a level are written.
+func (l *Logger) Enabled(level LogLevel) bool {
+	return l != nil && level >= l.level
+}
+
+func (l *Logger) log(level LogLevel, msg string, keyValues []interface{}) {
+	if !l.Enabled(level) {
+		return
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "time=%v level=%v msg=%q", time.Now().Format(time.RFC3339Nano), levelNames[level], msg)
+	b.WriteString(formatKeyValues(keyValues))
+	b.WriteByte('\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	io.WriteString(l.w, b.String())
+}
+
+// formatKeyValues formats key value pairs as " key=value", quoting values
+// with spaces.
+func formatKeyValues(keyValues []interface{}) string {
+	var b strings.Builder
+	for i := 0; i < len(keyValues); i += 2 {
+		var value interface{} = "<missing>"
+		if i+1 < len(keyValues) {
+			value = keyValues[i+1]
+		}
+		v := fmt.Sprint(value)
+		if strings.ContainsAny(v, " \t\"=") || v == "" {
+			v = fmt.Sprintf("%q", v)
+		}
+		fmt.Fprintf(&b, " %v=%v", keyValues[i], v)
+	}
+	return b.String()
+}
+
+// openLog opens a logger on the log file in the XDG state directory, as the
+// terminal belongs to the screen. The level is read from SNAKE_LOG_LEVEL. The
+// returned file has to be closed by the caller.
+func openLog() (*Logger, *os.File, error) {
+	base := os.Getenv("XDG_STATE_HOME")
+	if base == "" || !filepath.IsAbs(base) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, nil, err
+		}
+		base = filepath.Join(home, ".local", "state")
+	}
+	dir := filepath.Join(base, appDirName)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, nil, err
+	}
+	f, err := os.OpenFile(filepath.Join(dir, "snake.log"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, nil, err
+	}
+	return NewLogger(f, ParseLogLevel(os.Getenv("SNAKE_LOG_LEVEL"))), f, nil
+}
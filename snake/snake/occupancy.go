@@ -0,0 +1,136 @@
+package snake
+
+import "math/rand"
+
+// Occupancy is the occupancy grid of a board: the snake on every cell of the
+// arena and the cells that are free, without snake and food. The game keeps it
+// up to date on every move, growth and new round, so collisions and food
+// placement don't have to scan the snakes.
+type Occupancy struct {
+	width, height int
+	// owner is the snake number + 1 of the part on a cell, 0 if it is empty.
+	owner []int
+	food  []bool
+	// free holds the free cells in no order, slot is the position of a cell
+	// in free or -1 if it isn't free.
+	free []Coordinate
+	slot []int
+}
+
+// newOccupancy creates the occupancy grid of an empty board.
+func newOccupancy(board *Board) *Occupancy {
+	size := (board.width + 1) * (board.height + 1)
+	o := &Occupancy{
+		width:  board.width,
+		height: board.height,
+		owner:  make([]int, size),
+		food:   make([]bool, size),
+		free:   make([]Coordinate, 0, len(board.area)),
+		slot:   make([]int, size),
+	}
+	for i := range o.slot {
+		o.slot[i] = -1
+	}
+	for _, c := range board.area {
+		o.release(o.index(c))
+	}
+	return o
+}
+
+// index returns the cell index of a coordinate, -1 if it is not in the arena.
+// The parts a growing snake still has to place are off the arena at (0,0).
+func (o *Occupancy) index(c Coordinate) int {
+	if c.x <= 0 || c.y <= 0 || c.x >= o.width || c.y >= o.height {
+		return -1
+	}
+	return c.y*(o.width+1) + c.x
+}
+
+// Owner returns the number of the snake with a part on a cell.
+func (o *Occupancy) Owner(c Coordinate) (int, bool) {
+	i := o.index(c)
+	if i < 0 || o.owner[i] == 0 {
+		return 0, false
+	}
+	return o.owner[i] - 1, true
+}
+
+// RandomFree returns a free cell, every free cell with the same chance. It is
+// false if the board is full.
+func (o *Occupancy) RandomFree(r *rand.Rand) (Coordinate, bool) {
+	if len(o.free) == 0 {
+		return Coordinate{}, false
+	}
+	return o.free[r.Intn(len(o.free))], true
+}
+
+// occupy puts a part of a snake on a cell.
+func (o *Occupancy) occupy(c Coordinate, snakeNumber int) {
+	if i := o.index(c); i >= 0 {
+		o.owner[i] = snakeNumber + 1
+		o.take(i)
+	}
+}
+
+// vacate takes the snake part off a cell.
+func (o *Occupancy) vacate(c Coordinate) {
+	if i := o.index(c); i >= 0 {
+		o.owner[i] = 0
+		if !o.food[i] {
+			o.release(i)
+		}
+	}
+}
+
+func (o *Occupancy) addFood(c Coordinate) {
+	if i := o.index(c); i >= 0 {
+		o.food[i] = true
+		o.take(i)
+	}
+}
+
+func (o *Occupancy) removeFood(c Coordinate) {
+	if i := o.index(c); i >= 0 {
+		o.food[i] = false
+		if o.owner[i] == 0 {
+			o.release(i)
+		}
+	}
+}
+
+// take removes a cell from the free cells.
+func (o *Occupancy) take(i int) {
+	k := o.slot[i]
+	if k < 0 {
+		return
+	}
+	last := len(o.free) - 1
+	moved := o.free[last]
+	o.free[k] = moved
+	o.slot[o.index(moved)] = k
+	o.free = o.free[:last]
+	o.slot[i] = -1
+}
+
+// release adds a cell to the free cells.
+func (o *Occupancy) release(i int) {
+	if o.slot[i] >= 0 {
+		return
+	}
+	o.slot[i] = len(o.free)
+	o.free = append(o.free, newCoordinate(i%(o.width+1), i/(o.width+1)))
+}
+
+// buildOccupancy sets up the occupancy grid of the current snakes and food.
+// Must hold g.mu or own the game.
+func (g *Game) buildOccupancy() {
+	g.occupied = newOccupancy(g.Board)
+	for i, s := range g.Snakes {
+		for _, sp := range s.SnakeParts {
+			g.occupied.occupy(sp.Coordinate, i)
+		}
+	}
+	for _, f := range g.Food {
+		g.occupied.addFood(f.Coordinates)
+	}
+}
@@ -0,0 +1,89 @@
+package snake
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestOccupancy(t *testing.T) {
+	a, b := newCoordinate(1, 1), newCoordinate(2, 1)
+	tests := []struct {
+		name  string
+		apply func(o *Occupancy)
+		// owners are the snakes on a and b, -1 for none.
+		owners [2]int
+		free   int
+	}{
+		{"empty", func(o *Occupancy) {}, [2]int{-1, -1}, 4},
+		{"occupied", func(o *Occupancy) {
+			o.occupy(a, 0)
+			o.occupy(b, 1)
+		}, [2]int{0, 1}, 2},
+		{"vacated", func(o *Occupancy) {
+			o.occupy(a, 0)
+			o.vacate(a)
+		}, [2]int{-1, -1}, 4},
+		{"food", func(o *Occupancy) {
+			o.addFood(a)
+		}, [2]int{-1, -1}, 3},
+		{"eaten food", func(o *Occupancy) {
+			o.addFood(a)
+			o.occupy(a, 0)
+			o.removeFood(a)
+		}, [2]int{0, -1}, 3},
+		{"vacated food", func(o *Occupancy) {
+			o.addFood(a)
+			o.occupy(a, 0)
+			o.vacate(a)
+		}, [2]int{-1, -1}, 3},
+		{"off the arena", func(o *Occupancy) {
+			o.occupy(newCoordinate(0, 0), 0)
+			o.addFood(newCoordinate(3, 0))
+		}, [2]int{-1, -1}, 4},
+		{"taken twice", func(o *Occupancy) {
+			o.occupy(a, 0)
+			o.occupy(a, 1)
+			o.addFood(a)
+		}, [2]int{1, -1}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newOccupancy(newBoard(3, 3))
+			tt.apply(o)
+			for i, c := range []Coordinate{a, b} {
+				owner, ok := o.Owner(c)
+				if !ok {
+					owner = -1
+				}
+				if owner != tt.owners[i] {
+					t.Errorf("owner of %v = %v, want %v", c, owner, tt.owners[i])
+				}
+			}
+			if len(o.free) != tt.free {
+				t.Fatalf("%v free cells, want %v", len(o.free), tt.free)
+			}
+			for k, c := range o.free {
+				if _, ok := o.Owner(c); ok || o.food[o.index(c)] || o.slot[o.index(c)] != k {
+					t.Errorf("free cell %v is taken or out of its slot", c)
+				}
+			}
+		})
+	}
+}
+
+func TestOccupancyRandomFree(t *testing.T) {
+	o := newOccupancy(newBoard(3, 3))
+	o.occupy(newCoordinate(1, 1), 0)
+	o.occupy(newCoordinate(2, 1), 0)
+	o.addFood(newCoordinate(1, 2))
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 20; i++ {
+		if c, ok := o.RandomFree(r); !ok || c != newCoordinate(2, 2) {
+			t.Fatalf("RandomFree = %v %v, want 2,2", c, ok)
+		}
+	}
+	o.occupy(newCoordinate(2, 2), 1)
+	if c, ok := o.RandomFree(r); ok {
+		t.Errorf("RandomFree = %v on a full board", c)
+	}
+}
@@ -0,0 +1,145 @@
+package snake
+
+// opponentBot is a survival bot that also looks at the other snakes. Every
+// cell an opponent head can reach in the next tick is avoided, unless the bot
+// is longer than that opponent and moves before it in a tick: then the cell
+// is a target it goes for when it is next to it. A snake that moves first
+// enters the cell before the bot does, and a head entering a body kills the
+// mover, so those cells are always dangers. With the aggression of its
+// personality it also cuts off smaller snakes further away, see hunt. The
+// body cells that are free by the next move of the bot are free cells, see
+// fillFrees: the tails of the snakes that move before it, unless they sit
+// out a tick in mud.
+type opponentBot struct {
+	*survivalBot
+	// chase searches the way to a smaller snake around the cells it is
+	// going to be on, the prey reservations.
+	chase *spaceTimeSearch
+	prey  *reservationTable
+}
+
+func newOpponentBot(board *Board, level Difficulty, search GridSearch, style Personality, targets *foodTargets) *opponentBot {
+	b := &opponentBot{
+		survivalBot: newSurvivalBot(board, level, search, style, targets),
+		chase:       newSpaceTimeSearch(),
+		prey:        newReservationTable(),
+	}
+	b.chase.reserved = b.prey
+	return b
+}
+
+func (b *opponentBot) NextDirection(g *Game, snakeNumber int) int {
+	bodies := g.snakeBodies(snakeNumber)
+	body := bodies[snakeNumber]
+
+	g.fillGrid(b.grid, g.Snakes[snakeNumber])
+	for i, free := range b.grid.Frees {
+		if free == 1 {
+			c := b.grid.Coordinate(i)
+			b.grid.Set(c, g.Board.Terrain(c))
+		}
+	}
+	b.keepOut(g, snakeNumber, b.grid)
+	food, hasFood := b.targets.choose(g, snakeNumber, b.grid)
+
+	targets := make([]Coordinate, 0)
+	dangers := make([]Coordinate, 0)
+	for i, other := range bodies {
+		if i == snakeNumber || other == nil {
+			continue
+		}
+		for _, dir := range []int{Up, Left, Right, Down} {
+			c := other[0].step(dir)
+			if b.grid.Kind(c) == KindBlocker {
+				continue
+			}
+			// snakes move in the order of their numbers
+			if len(body) > len(other) && i > snakeNumber {
+				targets = append(targets, c)
+			} else {
+				dangers = append(dangers, c)
+			}
+		}
+	}
+
+	lines := b.preyLines(bodies, snakeNumber)
+	head := body[0]
+	for _, c := range targets {
+		if manhattan(head, c) == 1 && (!b.checks || b.isSafe(body, []Coordinate{c}, 0)) {
+			g.setBotPath(snakeNumber, []Coordinate{head, c})
+			return head.directionTo(c)
+		}
+	}
+
+	kinds := make([]int, len(dangers))
+	for i, c := range dangers {
+		kinds[i] = b.grid.Kind(c)
+		b.grid.Set(c, KindBlocker)
+	}
+	if dir, ok := b.hunt(g, snakeNumber, body, lines); ok {
+		return dir
+	}
+	if dir := b.decide(g, snakeNumber, body, food, hasFood); dir >= 0 {
+		return dir
+	}
+	// every move is risky, take the risk rather than a certain death
+	for i, c := range dangers {
+		b.grid.Set(c, kinds[i])
+	}
+	return b.decide(g, snakeNumber, body, food, hasFood)
+}
+
+// preyLine is the cells ahead of the head of a smaller snake and its length.
+type preyLine struct {
+	cells  []Coordinate
+	length int
+}
+
+// preyLines returns the free cells in front of the heads of the snakes that
+// are smaller than the bot, as far as the aggression of the bot.
+func (b *opponentBot) preyLines(bodies [][]Coordinate, snakeNumber int) []preyLine {
+	body := bodies[snakeNumber]
+	lines := make([]preyLine, 0)
+	for i, prey := range bodies {
+		if i == snakeNumber || prey == nil || len(prey) < 2 || len(prey) >= len(body) {
+			continue
+		}
+		dir := prey[1].directionTo(prey[0])
+		line := preyLine{length: len(prey)}
+		for c := prey[0].step(dir); float64(len(line.cells)) < b.style.Aggression && b.grid.Kind(c) != KindBlocker; c = c.step(dir) {
+			line.cells = append(line.cells, c)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// hunt cuts off a smaller snake: it goes for a cell ahead of its head that
+// the bot reaches before the snake does. The cells the snake is going to be
+// on are reserved, so the path keeps out of its way. It is false if there is
+// no such cell or no safe way to one.
+func (b *opponentBot) hunt(g *Game, snakeNumber int, body []Coordinate, lines []preyLine) (int, bool) {
+	head := body[0]
+	for _, line := range lines {
+		b.prey.clear()
+		for k, c := range line.cells {
+			b.prey.reserve(b.grid.Index(c), k+1, k+line.length+1)
+		}
+		for k, c := range line.cells {
+			path, _, found := b.chase.Path(b.grid, head, c)
+			if !found || len(path) < 2 || len(path)-1 > k || b.checks && !b.isSafe(body, path[1:2], 0) {
+				continue
+			}
+			g.setBotSearch(snakeNumber, b.chase)
+			g.setBotPath(snakeNumber, path)
+			return head.directionTo(path[1]), true
+		}
+	}
+	return 0, false
+}
+
+// isGrowing tells if a snake has just eaten: the grown part waits at 0,0
+// until the snake moves, and the tail stays in place for that move.
+func isGrowing(body []Coordinate) bool {
+	return body[len(body)-1] == Coordinate{}
+}
@@ -0,0 +1,112 @@
+package snake
+
+import "github.com/gdamore/tcell"
+
+// SearchTrace holds the cells a path search touched: the expanded (closed)
+// ones and the ones still open when it stopped.
+type SearchTrace struct {
+	Expanded []Coordinate
+	Open     []Coordinate
+}
+
+// Trace returns the cells touched by the last search of the pather.
+func (p *GridPather) Trace() SearchTrace {
+	trace := SearchTrace{
+		Expanded: make([]Coordinate, 0),
+		Open:     make([]Coordinate, 0),
+	}
+	for i, stamp := range p.stamp {
+		if stamp != p.gen {
+			continue
+		}
+		if p.nodes[i].isClosed {
+			trace.Expanded = append(trace.Expanded, p.nodes[i].coordinates)
+		} else if p.nodes[i].isWalkable {
+			trace.Open = append(trace.Open, p.nodes[i].coordinates)
+		}
+	}
+	return trace
+}
+
+func (g *Game) toggleHeatmap() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.ShowHeatmap = !g.ShowHeatmap
+	if !g.ShowHeatmap {
+		g.BotSearches = make(map[int]SearchTrace)
+	}
+}
+
+// setBotSearch stores the last search of a bot for the heatmap, if the
+// heatmap is shown.
+func (g *Game) setBotSearch(snakeNumber int, search GridSearch) {
+	if g.heatmapShown() {
+		g.setBotTrace(snakeNumber, search.Trace())
+	}
+}
+
+// setBotTrace stores the cells of the last search of a bot for the heatmap.
+func (g *Game) setBotTrace(snakeNumber int, trace SearchTrace) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.ShowHeatmap {
+		g.BotSearches[snakeNumber] = trace
+	}
+}
+
+func (g *Game) heatmapShown() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.ShowHeatmap
+}
+
+// Display the cells the bot searches expanded and left open.
+func (g *Game) drawHeatmap(s *Snapshot) {
+	if !s.ShowHeatmap {
+		return
+	}
+	heat := make(map[Coordinate]int)
+	for _, trace := range s.BotSearches {
+		for _, c := range trace.Open {
+			if heat[c] == 0 {
+				heat[c] = 1
+			}
+		}
+		for _, c := range trace.Expanded {
+			heat[c]++
+			if heat[c] == 1 {
+				heat[c] = 2
+			}
+		}
+	}
+	for c, h := range heat {
+		color := tcell.ColorNavy
+		switch {
+		case h >= 3:
+			color = tcell.ColorMaroon
+		case h == 2:
+			color = tcell.ColorPurple
+		}
+		g.Screen.SetContent(c.x, c.y, ' ', nil, tcell.StyleDefault.Background(color))
+	}
+}
+
+// Display the planned path of every bot as a dim trail in its color.
+func (g *Game) drawBotPaths(s *Snapshot) {
+	for j, path := range s.BotPaths {
+		if j >= len(s.Snakes) {
+			continue
+		}
+		style := tcell.StyleDefault.Foreground(g.snakeColor(s, j)).Dim(true)
+		for _, c := range path {
+			_, _, current, _ := g.Screen.GetContent(c.x, c.y)
+			g.Screen.SetContent(c.x, c.y, tcell.RuneBullet, nil, style.Background(backgroundOf(current)))
+		}
+	}
+}
+
+// backgroundOf returns the background color of a style.
+func backgroundOf(style tcell.Style) tcell.Color {
+	_, bg, _ := style.Decompose()
+	return bg
+}
@@ -0,0 +1,181 @@
+package snake
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"snake2/astar"
+)
+
+// pathBenchmarkSizes are the board sizes the pathfinders are compared on.
+var pathBenchmarkSizes = [][2]int{{50, 20}, {100, 50}, {200, 200}, {500, 500}}
+
+// BenchmarkPath compares the Pather based A*, the generic astar package on a
+// Grid and the GridPather. The benchmarks include building their world from
+// the board, as a bot has to do every tick.
+func BenchmarkPath(b *testing.B) {
+	for _, size := range pathBenchmarkSizes {
+		gr, from, to := benchmarkGrid(size[0], size[1])
+		b.Run(fmt.Sprintf("Pather/%vx%v", size[0], size[1]), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				w := gr.World()
+				Path(w.Tile(from.x, from.y), w.Tile(to.x, to.y))
+			}
+		})
+		b.Run(fmt.Sprintf("Generic/%vx%v", size[0], size[1]), func(b *testing.B) {
+			b.ReportAllocs()
+			work := NewGrid(gr.Width, gr.Height)
+			graph := work.Graph()
+			for i := 0; i < b.N; i++ {
+				copy(work.Kinds, gr.Kinds)
+				astar.Path(graph, from, to)
+			}
+		})
+		b.Run(fmt.Sprintf("Grid/%vx%v", size[0], size[1]), func(b *testing.B) {
+			b.ReportAllocs()
+			work := NewGrid(gr.Width, gr.Height)
+			p := NewGridPather()
+			for i := 0; i < b.N; i++ {
+				copy(work.Kinds, gr.Kinds)
+				p.Path(work, from, to)
+			}
+		})
+	}
+}
+
+// benchmarkGrid creates a board with snake like walls in it and a start and
+// goal in opposite corners.
+func benchmarkGrid(width, height int) (*Grid, Coordinate, Coordinate) {
+	gr := NewGrid(width+1, height+1)
+	gr.Reset()
+	r := rand.New(rand.NewSource(1))
+	walls := width * height / 60
+	for i := 0; i < walls; i++ {
+		c := newCoordinate(1+r.Intn(width-1), 1+r.Intn(height-1))
+		dir := r.Intn(4)
+		for j := 0; j < 10; j++ {
+			gr.Set(c, KindBlocker)
+			c = c.step(dir)
+			if r.Intn(4) == 0 {
+				dir = r.Intn(4)
+			}
+		}
+	}
+	from, to := newCoordinate(1, 1), newCoordinate(width-1, height-1)
+	gr.Set(from, KindFrom)
+	gr.Set(to, KindTo)
+	for _, c := range []Coordinate{from.step(Right), from.step(Down), to.step(Left), to.step(Up)} {
+		gr.Set(c, KindPlain)
+	}
+	return gr, from, to
+}
+
+// searchBoards are the boards the search algorithms are compared on.
+var searchBoards = []struct {
+	name  string
+	build func() (*Grid, Coordinate, Coordinate)
+}{
+	{"open/50x20", func() (*Grid, Coordinate, Coordinate) { return openGrid(50, 20) }},
+	{"walls/50x20", func() (*Grid, Coordinate, Coordinate) { return benchmarkGrid(50, 20) }},
+	{"terrain/50x20", func() (*Grid, Coordinate, Coordinate) { return terrainGrid(50, 20) }},
+	{"walls/200x200", func() (*Grid, Coordinate, Coordinate) { return benchmarkGrid(200, 200) }},
+}
+
+// BenchmarkSearch compares the search algorithms of the bots on an open
+// board, boards with walls and a board with terrain.
+func BenchmarkSearch(b *testing.B) {
+	for _, board := range searchBoards {
+		gr, from, to := board.build()
+		for _, name := range SearchNames() {
+			create := GridSearches[name]
+			b.Run(fmt.Sprintf("%v/%v", name, board.name), func(b *testing.B) {
+				b.ReportAllocs()
+				work := NewGrid(gr.Width, gr.Height)
+				search := create()
+				for i := 0; i < b.N; i++ {
+					copy(work.Kinds, gr.Kinds)
+					search.Path(work, from, to)
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkReplan compares the search algorithms on the same boards when they
+// replan every tick while a snake moves to the goal.
+func BenchmarkReplan(b *testing.B) {
+	for _, board := range searchBoards {
+		grids, heads, goal := replanGrids(board.build())
+		for _, name := range SearchNames() {
+			create := GridSearches[name]
+			b.Run(fmt.Sprintf("%v/%v", name, board.name), func(b *testing.B) {
+				b.ReportAllocs()
+				work := NewGrid(grids[0].Width, grids[0].Height)
+				search := create()
+				for i := 0; i < b.N; i++ {
+					for tick, gr := range grids {
+						copy(work.Kinds, gr.Kinds)
+						search.Path(work, heads[tick], goal)
+					}
+				}
+			})
+		}
+	}
+}
+
+// replanLength is the length of the snake of the replanning benchmarks.
+const replanLength = 20
+
+// replanGrids moves a snake along the shortest path of a board to its goal
+// and returns the grid of every tick as its bot sees it, with the head of the
+// snake in that tick. The searches replan the same goal on a grid that
+// changes by a few cells per tick.
+func replanGrids(base *Grid, from, to Coordinate) ([]*Grid, []Coordinate, Coordinate) {
+	path, _, _ := NewGridPather().Path(base, from, to)
+	path = append([]Coordinate{}, path...)
+	base.Set(from, KindPlain)
+	grids := make([]*Grid, 0, len(path))
+	heads := make([]Coordinate, 0, len(path))
+	for tick := 0; tick < len(path)-1; tick++ {
+		gr := NewGrid(base.Width, base.Height)
+		copy(gr.Kinds, base.Kinds)
+		for k := tick; k >= 0 && k > tick-replanLength; k-- {
+			gr.Set(path[k], KindBlocker)
+		}
+		gr.Set(path[tick], KindFrom)
+		grids = append(grids, gr)
+		heads = append(heads, path[tick])
+	}
+	return grids, heads, to
+}
+
+// openGrid creates an empty board with a start and goal in opposite corners.
+func openGrid(width, height int) (*Grid, Coordinate, Coordinate) {
+	gr := NewGrid(width+1, height+1)
+	gr.Reset()
+	from, to := newCoordinate(1, 1), newCoordinate(width-1, height-1)
+	gr.Set(from, KindFrom)
+	gr.Set(to, KindTo)
+	return gr, from, to
+}
+
+// terrainGrid creates a board with walls like benchmarkGrid and patches of
+// mud and ice on its free cells.
+func terrainGrid(width, height int) (*Grid, Coordinate, Coordinate) {
+	gr, from, to := benchmarkGrid(width, height)
+	r := rand.New(rand.NewSource(2))
+	for i := range gr.Kinds {
+		if gr.Kinds[i] != KindPlain {
+			continue
+		}
+		switch r.Intn(6) {
+		case 0:
+			gr.Kinds[i] = KindMud
+		case 1:
+			gr.Kinds[i] = KindIce
+		}
+	}
+	return gr, from, to
+}
@@ -26,6 +26,14 @@ const (
 	KindTo
 	// KindPath (●) is a tile to represent where the path is in the output.
 	KindPath
+	// KindMud (%) is a mud tile, a snake that enters it sits out its next
+	// move.
+	KindMud
+	// KindIce (=) is an ice tile, a snake on it can't turn.
+	KindIce
+	// KindGrass (") is a grass tile, a snake with its head in it is hidden
+	// from the bots of the other snakes.
+	KindGrass
 )
 
 // KindRunes map tile kinds to output runes.
@@ -37,6 +45,9 @@ var KindRunes = map[int]rune{
 	KindFrom:     'F',
 	KindTo:       'T',
 	KindPath:     '●',
+	KindMud:      '%',
+	KindIce:      '=',
+	KindGrass:    '"',
 }
 
 // RuneKinds map input runes to tile kinds.
@@ -47,6 +58,9 @@ var RuneKinds = map[rune]int{
 	'X': KindBlocker,
 	'F': KindFrom,
 	'T': KindTo,
+	'%': KindMud,
+	'=': KindIce,
+	'"': KindGrass,
 }
 
 // KindCosts map tile kinds to movement costs.
@@ -56,6 +70,9 @@ var KindCosts = map[int]float64{
 	KindTo:       1.0,
 	KindRiver:    2.0,
 	KindMountain: 3.0,
+	KindMud:      2.0,
+	KindIce:      2.0,
+	KindGrass:    1.0,
 }
 
 // A Tile is a tile in a grid which implements Pather.
@@ -97,8 +114,7 @@ func (t *Tile) PathNeighborCost(to Pather) float64 {
 func (t *Tile) PathEstimatedCost(to Pather) float64 {
 	toT := to.(*Tile)
 	//TODO valamiért ezek közül az egyik érték elveszik néha
-	if t.X == 0 || t.X == 0 {
-		fmt.Println("arghhhhhhhhhhhhhhhhhhh")
+	if t.X == 0 || t.Y == 0 {
 		return 9999999
 	}
 	absX := toT.X - t.X
@@ -0,0 +1,26 @@
+package snake
+
+import "testing"
+
+// TestPathMissingTile checks that a path to or from a missing tile is not
+// found, whether the tile is a nil *Tile or a nil Pather.
+func TestPathMissingTile(t *testing.T) {
+	w := ParseWorld(`
+F..
+...
+`)
+	tests := []struct {
+		name     string
+		from, to Pather
+	}{
+		{"no to tile", w.From(), w.To()},
+		{"tile off the world", w.From(), w.Tile(5, 5)},
+		{"no from tile", w.To(), w.Tile(2, 1)},
+		{"nil pather", w.From(), nil},
+	}
+	for _, tt := range tests {
+		if path, _, found := Path(tt.from, tt.to); found || path != nil {
+			t.Errorf("%v: found = %v, path = %v, want none", tt.name, found, path)
+		}
+	}
+}
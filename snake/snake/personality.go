@@ -0,0 +1,106 @@
+package snake
+
+import "encoding/json"
+
+// Personality is a named play style of a survival or opponent bot: the
+// weights it decides with. The room of a move is measured in cells, the
+// weights of wall avoidance and center preference are in cells of room too.
+type Personality struct {
+	Name string `json:"-"`
+	// FoodGreed is how readily the bot goes for food: at 1 it takes every
+	// food path, below that only paths up to that share of the board width
+	// plus height, at 0 it only looks for room.
+	FoodGreed float64 `json:"foodGreed"`
+	// Aggression is how far away, in cells, an opponent bot cuts off a
+	// smaller snake. At 0 it only goes for heads right next to it.
+	Aggression float64 `json:"aggression"`
+	// WallAvoidance is the room a move is worth less for every wall or body
+	// next to the cell it enters.
+	WallAvoidance float64 `json:"wallAvoidance"`
+	// CenterPreference is the room a move is worth less for every step the
+	// cell it enters is away from the center of the board.
+	CenterPreference float64 `json:"centerPreference"`
+	// RiskTolerance is the share of its length the room after a move may
+	// fall short of before the bot takes the move as unsafe.
+	RiskTolerance float64 `json:"riskTolerance"`
+}
+
+// Personalities of the bot settings.
+const (
+	PersonalityBalanced = "balanced"
+	PersonalityGreedy   = "greedy"
+	PersonalityHunter   = "hunter"
+	PersonalityCautious = "cautious"
+)
+
+// Personalities are the built in personalities by name, the settings can add
+// more or change them.
+var Personalities = map[string]Personality{
+	PersonalityBalanced: {Name: PersonalityBalanced, FoodGreed: 1, Aggression: 1},
+	PersonalityGreedy:   {Name: PersonalityGreedy, FoodGreed: 1, RiskTolerance: 0.3},
+	PersonalityHunter:   {Name: PersonalityHunter, FoodGreed: 0.5, Aggression: 8, CenterPreference: 1, RiskTolerance: 0.1},
+	PersonalityCautious: {Name: PersonalityCautious, FoodGreed: 0.4, WallAvoidance: 2, CenterPreference: 0.5},
+}
+
+// UnmarshalJSON reads a personality of the settings, the weights it leaves
+// out are the ones of the balanced personality.
+func (p *Personality) UnmarshalJSON(data []byte) error {
+	type weights Personality
+	w := weights(Personalities[PersonalityBalanced])
+	if err := json.Unmarshal(data, &w); err != nil {
+		return err
+	}
+	*p = Personality(w)
+	return nil
+}
+
+// personalityOf returns the personality of a name, the ones of the settings
+// before the built in ones. Bots are balanced if the name is empty or
+// unknown.
+func (g *Game) personalityOf(name string) Personality {
+	if name == "" {
+		return Personalities[PersonalityBalanced]
+	}
+	if p, ok := g.settings.Personalities[name]; ok {
+		p.Name = name
+		return p
+	}
+	if p, ok := Personalities[name]; ok {
+		return p
+	}
+	g.logger.Warn("unknown personality", "personality", name, "using", PersonalityBalanced)
+	return Personalities[PersonalityBalanced]
+}
+
+// wantsFood tells if the bot goes for food along a path.
+func (p Personality) wantsFood(gr *Grid, path []Coordinate) bool {
+	if p.FoodGreed >= 1 {
+		return true
+	}
+	return float64(len(path)-1) <= p.FoodGreed*float64(gr.Width+gr.Height)
+}
+
+// enoughRoom tells if the room a snake can reach is enough for its length.
+func (p Personality) enoughRoom(area int, length int) bool {
+	return float64(area) >= float64(length)*(1-p.RiskTolerance)
+}
+
+// penalty is the room a move into a cell is worth less for its walls and its
+// distance from the center.
+func (p Personality) penalty(gr *Grid, c Coordinate) float64 {
+	penalty := 0.0
+	if p.WallAvoidance != 0 {
+		walls := 0
+		for dir := Up; dir <= Down; dir++ {
+			if gr.Kind(c.step(dir)) == KindBlocker {
+				walls++
+			}
+		}
+		penalty += p.WallAvoidance * float64(walls)
+	}
+	if p.CenterPreference != 0 {
+		center := newCoordinate(gr.Width/2, gr.Height/2)
+		penalty += p.CenterPreference * float64(manhattan(c, center))
+	}
+	return penalty
+}
@@ -0,0 +1,113 @@
+package snake
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestPersonalityWantsFood(t *testing.T) {
+	gr := NewGrid(10, 10)
+	path := func(moves int) []Coordinate { return make([]Coordinate, moves+1) }
+	tests := []struct {
+		greed float64
+		moves int
+		want  bool
+	}{
+		{1, 100, true},
+		{0.5, 10, true},
+		{0.5, 11, false},
+		{0, 0, true},
+		{0, 1, false},
+	}
+	for _, tt := range tests {
+		p := Personality{FoodGreed: tt.greed}
+		if got := p.wantsFood(gr, path(tt.moves)); got != tt.want {
+			t.Errorf("greed %v, %v moves: wantsFood = %v, want %v", tt.greed, tt.moves, got, tt.want)
+		}
+	}
+}
+
+func TestPersonalityEnoughRoom(t *testing.T) {
+	tests := []struct {
+		risk         float64
+		area, length int
+		want         bool
+	}{
+		{0, 10, 10, true},
+		{0, 9, 10, false},
+		{0.3, 7, 10, true},
+		{0.3, 6, 10, false},
+	}
+	for _, tt := range tests {
+		p := Personality{RiskTolerance: tt.risk}
+		if got := p.enoughRoom(tt.area, tt.length); got != tt.want {
+			t.Errorf("risk %v, room %v for %v: enoughRoom = %v, want %v", tt.risk, tt.area, tt.length, got, tt.want)
+		}
+	}
+}
+
+func TestPersonalityPenalty(t *testing.T) {
+	gr, _, _ := textGrid(
+		"#######",
+		"#.....#",
+		"#.....#",
+		"#.....#",
+		"#.....#",
+		"#.....#",
+		"#######",
+	)
+	tests := []struct {
+		name string
+		p    Personality
+		c    Coordinate
+		want float64
+	}{
+		{"none", Personality{}, newCoordinate(1, 1), 0},
+		{"corner", Personality{WallAvoidance: 2}, newCoordinate(1, 1), 4},
+		{"side", Personality{WallAvoidance: 2}, newCoordinate(3, 1), 2},
+		{"open", Personality{WallAvoidance: 2}, newCoordinate(3, 3), 0},
+		{"center", Personality{CenterPreference: 1}, newCoordinate(3, 3), 0},
+		{"off center", Personality{CenterPreference: 0.5}, newCoordinate(1, 1), 2},
+		{"both", Personality{WallAvoidance: 1, CenterPreference: 1}, newCoordinate(1, 1), 6},
+	}
+	for _, tt := range tests {
+		if got := tt.p.penalty(gr, tt.c); got != tt.want {
+			t.Errorf("%v: penalty = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPersonalityUnmarshalJSON(t *testing.T) {
+	var p Personality
+	if err := json.Unmarshal([]byte(`{"wallAvoidance": 3}`), &p); err != nil {
+		t.Fatal(err)
+	}
+	want := Personalities[PersonalityBalanced]
+	want.WallAvoidance = 3
+	if p != want {
+		t.Errorf("got %+v, want %+v", p, want)
+	}
+}
+
+func TestPersonalityOf(t *testing.T) {
+	g := &Game{}
+	g.settings.Personalities = map[string]Personality{
+		"custom":          {FoodGreed: 0.2},
+		PersonalityHunter: {Aggression: 2},
+	}
+	tests := []struct {
+		name string
+		want Personality
+	}{
+		{"", Personalities[PersonalityBalanced]},
+		{"unknown", Personalities[PersonalityBalanced]},
+		{PersonalityGreedy, Personalities[PersonalityGreedy]},
+		{"custom", Personality{Name: "custom", FoodGreed: 0.2}},
+		{PersonalityHunter, Personality{Name: PersonalityHunter, Aggression: 2}},
+	}
+	for _, tt := range tests {
+		if got := g.personalityOf(tt.name); got != tt.want {
+			t.Errorf("personalityOf(%q) = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -1,35 +0,0 @@
-package snake
-
-// A priorityQueue implements heap.Interface and holds Nodes.  The
-// priorityQueue is used to track open nodes by rank.
-type priorityQueue []*node
-
-func (pq priorityQueue) Len() int {
-	return len(pq)
-}
-
-func (pq priorityQueue) Less(i, j int) bool {
-	return pq[i].rank < pq[j].rank
-}
-
-func (pq priorityQueue) Swap(i, j int) {
-	pq[i], pq[j] = pq[j], pq[i]
-	pq[i].index = i
-	pq[j].index = j
-}
-
-func (pq *priorityQueue) Push(x interface{}) {
-	n := len(*pq)
-	no := x.(*node)
-	no.index = n
-	*pq = append(*pq, no)
-}
-
-func (pq *priorityQueue) Pop() interface{} {
-	old := *pq
-	n := len(old)
-	no := old[n-1]
-	no.index = -1
-	*pq = old[0 : n-1]
-	return no
-}
@@ -2,13 +2,19 @@ package snake
 
 import (
 	"encoding/json"
-	"fmt"
 	"io/ioutil"
 	"os"
 )
 
 type PlayersControlSettings struct {
 	PlayersControlSettings []PlayerControlSetting `json:"playerControlSetting"`
+	BotSettings            []BotSetting           `json:"botSetting"`
+	Terrain                TerrainSetting         `json:"terrain"`
+	// DecisionMs is the time the bots have to decide in a tick.
+	DecisionMs int `json:"decisionMs"`
+	// Personalities are the personalities of the bots by name, next to the
+	// built in ones.
+	Personalities map[string]Personality `json:"personalities"`
 }
 
 type PlayerControlSetting struct {
@@ -19,14 +25,38 @@ type PlayerControlSetting struct {
 	Left  string `json:"left"`
 }
 
-func controlls(fileName string) PlayersControlSettings {
+type BotSetting struct {
+	Type       string `json:"type"`
+	Difficulty string `json:"difficulty"`
+	BudgetMs   int    `json:"budgetMs"`
+	Search     string `json:"search"`
+	// Team is the name of the team of the bot, bots of a team plan together.
+	Team string `json:"team"`
+	// Personality is the name of the play style of a survival or opponent
+	// bot.
+	Personality string `json:"personality"`
+	// Target is how the bot picks its food when there is more than one.
+	Target string `json:"target"`
+}
+
+// TerrainSetting is the number of terrain patches of every kind on the board
+// and the number of cells of a patch.
+type TerrainSetting struct {
+	Mud       int `json:"mud"`
+	Ice       int `json:"ice"`
+	Grass     int `json:"grass"`
+	PatchSize int `json:"patchSize"`
+}
+
+func (g *Game) controlls(fileName string) PlayersControlSettings {
 	// Open our jsonFile
 	jsonFile, err := os.Open(fileName)
 	// if we os.Open returns an error then handle it
 	if err != nil {
-		fmt.Println(err)
+		g.logger.Error("control settings not opened", "file", fileName, "err", err)
+	} else {
+		g.logger.Info("control settings opened", "file", fileName)
 	}
-	fmt.Println("Successfully Opened control.json")
 	defer jsonFile.Close()
 	byteValue, _ := ioutil.ReadAll(jsonFile)
 
@@ -0,0 +1,959 @@
+package snake
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A Scenario is a game situation written as text, used to set up rule and bot
+// tests and to save a tick of a live game. A scenario is a header of
+// directives, one per line, followed by the board:
+//
+//	# the bot must not enter the pocket
+//	bot b survival
+//	move a down left left
+//	ticks 4
+//	expect alive b
+//	expect avoid b 3,2 4,2 3,3 4,3
+//	board
+//	###########
+//	#.aaaa....#
+//	#.a..a....#
+//	#.a..Bbbb.#
+//	#.aaA.....#
+//	#.......*.#
+//	###########
+//
+// Lines starting with # in the header are comments. The directives are:
+//
+//	name <text>                 name of the scenario
+//	seed <n>                    seed of the food placement
+//	ticks <n>                   number of ticks to run
+//	bot <snake> <type> [level]  the snake is a bot of a type and difficulty
+//	search <snake> <algorithm>  search algorithm of the bot of the snake
+//	team <snake> <name>         team of the bot of the snake
+//	personality <snake> <name>  built in personality of the bot of the snake
+//	target <snake> <targeting>  food targeting of the bot of the snake
+//	dir <snake> <direction>     direction of the snake
+//	score <snake> <n>           score of the snake
+//	grow <snake> <n>            parts the snake still grows by
+//	food <x>,<y> [bonus]        food that is not on the board, e.g. under a
+//	                            snake
+//	terrain <kind> <x>,<y>...   terrain that is not on the board
+//	move <snake> <direction>... moves of a player snake, one per tick, - keeps
+//	                            the direction
+//	expect <outcome>            outcome after the ticks, see Expectation
+//
+// On the board # is the wall around the arena, . is an empty cell, * is food,
+// $ is bonus food and %, = and " are mud, ice and grass. Snakes are named by the letters a to
+// z in the order of the game, the players first. The head of a snake is its
+// upper case letter and its body is its lower case letter. The body is found
+// by walking from the head, a body part may be written as the digit of its
+// place in the body instead, modulo 10, where the walk is ambiguous. The
+// direction of a snake defaults to the one from its neck to its head.
+type Scenario struct {
+	Name  string
+	Seed  int64
+	Ticks int
+	// Width and Height are the size of the Board, the walls are at 0 and at
+	// Width and Height.
+	Width, Height int
+	Snakes        []ScenarioSnake
+	Food          []Coordinate
+	// Bonus are the cells of Food with bonus food.
+	Bonus map[Coordinate]bool
+	// Terrain is the tile kind of the cells that are not plain.
+	Terrain map[Coordinate]int
+	Expect  []Expectation
+}
+
+// ScenarioSnake is a snake of a scenario.
+type ScenarioSnake struct {
+	Body      []Coordinate
+	Direction int
+	Score     int
+	Grow      int
+	// Bot is the setting of a bot snake, nil for players.
+	Bot *BotSetting
+	// Moves are the directions of a player snake per tick, negative keeps
+	// the direction.
+	Moves []int
+}
+
+// Expectation kinds.
+const (
+	ExpectAlive   = "alive"
+	ExpectDead    = "dead"
+	ExpectOver    = "over"
+	ExpectRunning = "running"
+	ExpectHead    = "head"
+	ExpectLength  = "length"
+	ExpectScore   = "score"
+	ExpectAvoid   = "avoid"
+)
+
+// Expectation is an outcome of a scenario:
+//
+//	alive <snake>            the snake is alive at the end
+//	dead <snake> [cause]     the snake died, of a cause like wall, self or "snake P2"
+//	over                     the game is over
+//	running                  the game is not over
+//	head <snake> <x>,<y>     the head of the snake is at a cell
+//	length <snake> <n>       the snake has n parts, including pending growth
+//	score <snake> <n>        the snake scored n points
+//	avoid <snake> <x>,<y>... the head of the snake never enters the cells
+type Expectation struct {
+	Kind  string
+	Snake int
+	Value int
+	Cause string
+	Cells []Coordinate
+}
+
+// LoadScenario reads a scenario from a file.
+func LoadScenario(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	sc, err := ParseScenario(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", path, err)
+	}
+	return sc, nil
+}
+
+// ParseScenario parses the textual representation of a scenario.
+func ParseScenario(input string) (*Scenario, error) {
+	lines := strings.Split(strings.ReplaceAll(input, "\r\n", "\n"), "\n")
+	sc := &Scenario{Bonus: map[Coordinate]bool{}, Terrain: map[Coordinate]int{}}
+	directives := make([][]string, 0)
+	lineNumbers := make([]int, 0)
+	boardStart := -1
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if line == "board" {
+			boardStart = i + 1
+			break
+		}
+		directives = append(directives, strings.Fields(line))
+		lineNumbers = append(lineNumbers, i+1)
+	}
+	if boardStart < 0 {
+		return nil, fmt.Errorf("scenario has no board")
+	}
+	rows := make([]string, 0)
+	for _, line := range lines[boardStart:] {
+		line = strings.TrimRight(line, " \t")
+		if line == "" {
+			if len(rows) > 0 {
+				break
+			}
+			continue
+		}
+		rows = append(rows, line)
+	}
+	if err := sc.parseBoard(rows, boardStart); err != nil {
+		return nil, err
+	}
+	for i, fields := range directives {
+		if err := sc.parseDirective(fields); err != nil {
+			return nil, fmt.Errorf("line %v: %w", lineNumbers[i], err)
+		}
+	}
+	for i, s := range sc.Snakes {
+		if i > 0 && s.Bot == nil && sc.Snakes[i-1].Bot != nil {
+			return nil, fmt.Errorf("player snake %c comes after a bot, players have to be first", snakeLetter(i))
+		}
+		if s.Bot != nil && len(s.Moves) > 0 {
+			return nil, fmt.Errorf("snake %c is a bot and can't have moves", snakeLetter(i))
+		}
+	}
+	return sc, nil
+}
+
+// parseBoard reads the walls, food and snakes of the board rows. start is the
+// line number of the first row.
+func (sc *Scenario) parseBoard(rows []string, start int) error {
+	if len(rows) < 3 {
+		return fmt.Errorf("line %v: the board needs at least 3 rows", start)
+	}
+	cells := make([][]rune, len(rows))
+	for y, row := range rows {
+		cells[y] = []rune(row)
+		if len(cells[y]) != len(cells[0]) {
+			return fmt.Errorf("line %v: board rows have different lengths", start+y+1)
+		}
+	}
+	sc.Width, sc.Height = len(cells[0])-1, len(cells)-1
+	if sc.Width < 2 {
+		return fmt.Errorf("line %v: the board needs at least 3 columns", start)
+	}
+
+	heads := map[int]Coordinate{}
+	for y, row := range cells {
+		for x, r := range row {
+			c := newCoordinate(x, y)
+			wall := x == 0 || y == 0 || x == sc.Width || y == sc.Height
+			switch {
+			case wall && r != '#':
+				return fmt.Errorf("line %v: the board has to be surrounded by walls", start+y+1)
+			case wall:
+			case r == '#':
+				return fmt.Errorf("line %v: walls inside the arena are not supported", start+y+1)
+			case r == '*' || r == '$':
+				sc.Food = append(sc.Food, c)
+				if r == '$' {
+					sc.Bonus[c] = true
+				}
+			case terrainNames[RuneKinds[r]] != "":
+				sc.Terrain[c] = RuneKinds[r]
+			case r >= 'A' && r <= 'Z':
+				if _, ok := heads[int(r-'A')]; ok {
+					return fmt.Errorf("line %v: snake %c has two heads", start+y+1, r-'A'+'a')
+				}
+				heads[int(r-'A')] = c
+			case r == '.' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9':
+			default:
+				return fmt.Errorf("line %v: unknown cell %q", start+y+1, r)
+			}
+		}
+	}
+
+	sc.Snakes = make([]ScenarioSnake, len(heads))
+	visited := map[Coordinate]bool{}
+	for i := range sc.Snakes {
+		head, ok := heads[i]
+		if !ok {
+			return fmt.Errorf("snake %c has no head", snakeLetter(i))
+		}
+		body, err := walkBody(cells, head, snakeLetter(i), visited)
+		if err != nil {
+			return err
+		}
+		dir := Up
+		if len(body) > 1 {
+			dir = body[1].directionTo(body[0])
+		}
+		sc.Snakes[i] = ScenarioSnake{Body: body, Direction: dir}
+	}
+	for y, row := range cells {
+		for x, r := range row {
+			isBody := r >= 'a' && r <= 'z' || r >= '0' && r <= '9'
+			if isBody && !visited[newCoordinate(x, y)] {
+				return fmt.Errorf("line %v: body part %q at %v,%v belongs to no snake", start+y+1, r, x, y)
+			}
+		}
+	}
+	return nil
+}
+
+// walkBody follows the body of a snake from its head. The next part is the
+// neighbour with the letter of the snake or the digit of the place of the
+// part, a matching digit wins over letters.
+func walkBody(cells [][]rune, head Coordinate, letter rune, visited map[Coordinate]bool) ([]Coordinate, error) {
+	body := []Coordinate{head}
+	visited[head] = true
+	for {
+		digit := rune('0' + len(body)%10)
+		var letters, digits []Coordinate
+		for _, dir := range []int{Up, Left, Right, Down} {
+			c := body[len(body)-1].step(dir)
+			if c.y < 0 || c.y >= len(cells) || c.x < 0 || c.x >= len(cells[c.y]) || visited[c] {
+				continue
+			}
+			switch cells[c.y][c.x] {
+			case letter:
+				letters = append(letters, c)
+			case digit:
+				digits = append(digits, c)
+			}
+		}
+		next := digits
+		if len(next) == 0 {
+			next = letters
+		}
+		switch {
+		case len(next) == 0:
+			return body, nil
+		case len(next) > 1:
+			c := body[len(body)-1]
+			return nil, fmt.Errorf("the body of snake %c is ambiguous after %v,%v, number its parts", letter, c.x, c.y)
+		}
+		visited[next[0]] = true
+		body = append(body, next[0])
+	}
+}
+
+// parseDirective reads one header line of a scenario.
+func (sc *Scenario) parseDirective(fields []string) error {
+	name, args := fields[0], fields[1:]
+	argc := func(min, max int) error {
+		if len(args) < min || len(args) > max {
+			return fmt.Errorf("wrong number of arguments for %v", name)
+		}
+		return nil
+	}
+	var err error
+	switch name {
+	case "name":
+		sc.Name = strings.Join(args, " ")
+	case "seed":
+		if err := argc(1, 1); err != nil {
+			return err
+		}
+		sc.Seed, err = strconv.ParseInt(args[0], 10, 64)
+	case "ticks":
+		if err := argc(1, 1); err != nil {
+			return err
+		}
+		sc.Ticks, err = parseCount(args[0])
+	case "food":
+		if err := argc(1, 2); err != nil {
+			return err
+		}
+		c, err := sc.parseCell(args[0])
+		if err != nil {
+			return err
+		}
+		if len(args) == 2 && args[1] != "bonus" {
+			return fmt.Errorf("unknown food %q", args[1])
+		}
+		sc.Food = append(sc.Food, c)
+		if len(args) == 2 {
+			sc.Bonus[c] = true
+		}
+	case "terrain":
+		if err := argc(2, 1<<30); err != nil {
+			return err
+		}
+		kind, ok := terrainKind(args[0])
+		if !ok {
+			return fmt.Errorf("unknown terrain %q", args[0])
+		}
+		for _, arg := range args[1:] {
+			c, err := sc.parseCell(arg)
+			if err != nil {
+				return err
+			}
+			sc.Terrain[c] = kind
+		}
+	case "bot", "search", "team", "personality", "target", "dir", "score", "grow", "move":
+		if err := argc(1, 1<<30); err != nil {
+			return err
+		}
+		i, err := sc.parseSnake(args[0])
+		if err != nil {
+			return err
+		}
+		return sc.parseSnakeDirective(name, &sc.Snakes[i], args[1:])
+	case "expect":
+		if err := argc(1, 1<<30); err != nil {
+			return err
+		}
+		e, err := sc.parseExpectation(args)
+		if err != nil {
+			return err
+		}
+		sc.Expect = append(sc.Expect, e)
+	default:
+		return fmt.Errorf("unknown directive %q", name)
+	}
+	return err
+}
+
+// parseSnakeDirective reads a directive about one snake.
+func (sc *Scenario) parseSnakeDirective(name string, s *ScenarioSnake, args []string) error {
+	var err error
+	switch name {
+	case "bot":
+		if len(args) < 1 || len(args) > 2 {
+			return fmt.Errorf("bot needs a type and an optional difficulty")
+		}
+		s.Bot = &BotSetting{Type: args[0]}
+		if len(args) == 2 {
+			s.Bot.Difficulty = args[1]
+		}
+	case "search":
+		if len(args) != 1 {
+			return fmt.Errorf("search needs an algorithm")
+		}
+		if s.Bot == nil {
+			return fmt.Errorf("search needs a bot directive first")
+		}
+		if _, ok := GridSearches[args[0]]; !ok {
+			return fmt.Errorf("unknown search algorithm %q", args[0])
+		}
+		s.Bot.Search = args[0]
+	case "team":
+		if len(args) != 1 {
+			return fmt.Errorf("team needs a name")
+		}
+		if s.Bot == nil {
+			return fmt.Errorf("team needs a bot directive first")
+		}
+		s.Bot.Team = args[0]
+	case "personality":
+		if len(args) != 1 {
+			return fmt.Errorf("personality needs a name")
+		}
+		if s.Bot == nil {
+			return fmt.Errorf("personality needs a bot directive first")
+		}
+		if _, ok := Personalities[args[0]]; !ok {
+			return fmt.Errorf("unknown personality %q", args[0])
+		}
+		s.Bot.Personality = args[0]
+	case "target":
+		if len(args) != 1 {
+			return fmt.Errorf("target needs a food targeting")
+		}
+		if s.Bot == nil {
+			return fmt.Errorf("target needs a bot directive first")
+		}
+		if !isFoodTarget(args[0]) {
+			return fmt.Errorf("unknown food targeting %q", args[0])
+		}
+		s.Bot.Target = args[0]
+	case "dir":
+		if len(args) != 1 {
+			return fmt.Errorf("dir needs a direction")
+		}
+		s.Direction, err = parseDirection(args[0])
+	case "score", "grow":
+		if len(args) != 1 {
+			return fmt.Errorf("%v needs a number", name)
+		}
+		n, err := parseCount(args[0])
+		if err != nil {
+			return err
+		}
+		if name == "score" {
+			s.Score = n
+		} else {
+			s.Grow = n
+		}
+	case "move":
+		for _, arg := range args {
+			dir := -1
+			if arg != "-" {
+				if dir, err = parseDirection(arg); err != nil {
+					return err
+				}
+			}
+			s.Moves = append(s.Moves, dir)
+		}
+	}
+	return err
+}
+
+// parseExpectation reads the arguments of an expect directive.
+func (sc *Scenario) parseExpectation(args []string) (Expectation, error) {
+	e := Expectation{Kind: args[0]}
+	args = args[1:]
+	switch e.Kind {
+	case ExpectOver, ExpectRunning:
+		if len(args) != 0 {
+			return e, fmt.Errorf("%v has no arguments", e.Kind)
+		}
+		return e, nil
+	case ExpectAlive, ExpectDead, ExpectHead, ExpectLength, ExpectScore, ExpectAvoid:
+	default:
+		return e, fmt.Errorf("unknown expectation %q", e.Kind)
+	}
+	if len(args) == 0 {
+		return e, fmt.Errorf("%v needs a snake", e.Kind)
+	}
+	var err error
+	if e.Snake, err = sc.parseSnake(args[0]); err != nil {
+		return e, err
+	}
+	args = args[1:]
+	switch e.Kind {
+	case ExpectAlive:
+		if len(args) != 0 {
+			return e, fmt.Errorf("alive needs only a snake")
+		}
+	case ExpectDead:
+		e.Cause = strings.Join(args, " ")
+	case ExpectLength, ExpectScore:
+		if len(args) != 1 {
+			return e, fmt.Errorf("%v needs a number", e.Kind)
+		}
+		e.Value, err = parseCount(args[0])
+	case ExpectHead, ExpectAvoid:
+		if len(args) == 0 || e.Kind == ExpectHead && len(args) != 1 {
+			return e, fmt.Errorf("wrong number of cells for %v", e.Kind)
+		}
+		for _, arg := range args {
+			c, err := sc.parseCell(arg)
+			if err != nil {
+				return e, err
+			}
+			e.Cells = append(e.Cells, c)
+		}
+	}
+	return e, err
+}
+
+// parseSnake returns the index of a snake letter.
+func (sc *Scenario) parseSnake(arg string) (int, error) {
+	if len(arg) != 1 || arg[0] < 'a' || int(arg[0]-'a') >= len(sc.Snakes) {
+		return 0, fmt.Errorf("unknown snake %q", arg)
+	}
+	return int(arg[0] - 'a'), nil
+}
+
+// parseCell reads a coordinate inside the arena written as x,y.
+func (sc *Scenario) parseCell(arg string) (Coordinate, error) {
+	parts := strings.Split(arg, ",")
+	if len(parts) == 2 {
+		x, errX := strconv.Atoi(parts[0])
+		y, errY := strconv.Atoi(parts[1])
+		if errX == nil && errY == nil && x > 0 && y > 0 && x < sc.Width && y < sc.Height {
+			return newCoordinate(x, y), nil
+		}
+	}
+	return Coordinate{}, fmt.Errorf("%q is not a cell of the arena", arg)
+}
+
+// parseCount reads a number that is not negative.
+func parseCount(arg string) (int, error) {
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%q is not a count", arg)
+	}
+	return n, nil
+}
+
+// parseDirection reads a direction name or its first letter.
+func parseDirection(arg string) (int, error) {
+	for dir, name := range directionNames {
+		if arg == name || arg == name[:1] {
+			return dir, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown direction %q", arg)
+}
+
+// terrainKind returns the tile kind of a terrain name.
+func terrainKind(name string) (int, bool) {
+	for kind, terrainName := range terrainNames {
+		if name == terrainName {
+			return kind, true
+		}
+	}
+	return 0, false
+}
+
+// snakeLetter returns the letter of a snake in a scenario.
+func snakeLetter(i int) rune {
+	return rune('a' + i)
+}
+
+// NewGame builds a headless game in the situation of the scenario. It has no
+// screen, saves nothing and is started, so it can be moved forward with
+// updateItemState right away.
+func (sc *Scenario) NewGame() (*Game, error) {
+	if len(sc.Snakes) == 0 {
+		return nil, fmt.Errorf("scenario has no snakes")
+	}
+	game := &Game{
+		Board:       newBoard(sc.Width, sc.Height),
+		Speed:       500 * time.Millisecond,
+		Snakes:      make([]*Snake, 0, len(sc.Snakes)),
+		Food:        make([]Food, 0, len(sc.Food)),
+		FoodNumber:  len(sc.Food),
+		BotPaths:    make(map[int][]Coordinate),
+		BotSearches: make(map[int]SearchTrace),
+		queue:       make(chan Event, eventQueueSize),
+		rand:        rand.New(rand.NewSource(sc.Seed)),
+		seed:        sc.Seed,
+		headless:    true,
+		IsStart:     true,
+	}
+	for c, kind := range sc.Terrain {
+		game.Board.setTerrain(c, kind)
+	}
+	for _, s := range sc.Snakes {
+		snake := &Snake{Direction: s.Direction, Score: s.Score, IsBot: s.Bot != nil}
+		for j, c := range s.Body {
+			letter := "O"
+			if j == 0 {
+				letter = "H"
+			}
+			snake.SnakeParts = append(snake.SnakeParts, *newSnakePart(c, letter))
+		}
+		for j := 0; j < s.Grow; j++ {
+			// the same part eat adds until the snake moves
+			snake.SnakeParts = append(snake.SnakeParts, *newSnakePart(newCoordinate(0, 0), "O"))
+		}
+		game.Snakes = append(game.Snakes, snake)
+		if s.Bot != nil {
+			game.BotNumber++
+			game.settings.BotSettings = append(game.settings.BotSettings, *s.Bot)
+		} else {
+			game.PlayerNumber++
+		}
+	}
+	for _, c := range sc.Food {
+		game.Food = append(game.Food, newFood(c.x, c.y, sc.Bonus[c]))
+	}
+	game.highScoreMode = highScoreMode(game.PlayerNumber, game.BotNumber, game.FoodNumber, game.Board)
+	game.stats = newGameStats(game.highScoreMode, game.Snakes)
+	game.buildOccupancy()
+	game.publish()
+	return game, nil
+}
+
+// Run plays the scenario: every tick the bots decide and the players make
+// their moves, then the game moves on, until the ticks are over or the game
+// ends. It returns the game and the expectations that failed.
+func (sc *Scenario) Run() (*Game, []string, error) {
+	game, err := sc.NewGame()
+	if err != nil {
+		return nil, nil, err
+	}
+	bots := make([]Bot, game.BotNumber)
+	for i := range bots {
+		bots[i] = game.newBot(i)
+	}
+	pool := newBotPool(game, bots)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pool.start(ctx, func(f func()) { go f() })
+
+	failures := make([]string, 0)
+	for tick := 1; tick <= sc.Ticks && !game.hasEnded(); tick++ {
+		for i, s := range sc.Snakes {
+			if s.Bot == nil && tick <= len(s.Moves) {
+				game.changeDirection(i, s.Moves[tick-1])
+			}
+		}
+		for _, d := range pool.decideAll(game.botView(game.Snapshot()), tick) {
+			game.applyDecision(d)
+		}
+		game.updateItemState()
+		game.publish()
+
+		for _, e := range sc.Expect {
+			if e.Kind != ExpectAvoid {
+				continue
+			}
+			head := game.Snakes[e.Snake].SnakeParts[0].Coordinate
+			for _, c := range e.Cells {
+				if head == c {
+					failures = append(failures, fmt.Sprintf("tick %v: %v", tick, e.failure(fmt.Sprintf("entered %v,%v", c.x, c.y))))
+				}
+			}
+		}
+	}
+	for _, e := range sc.Expect {
+		if msg := e.check(game); msg != "" {
+			failures = append(failures, msg)
+		}
+	}
+	return game, failures, nil
+}
+
+// check returns why an expectation failed at the end of a scenario, it is
+// empty if it holds. Avoid is checked while the scenario runs.
+func (e Expectation) check(g *Game) string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	var stats SnakeStats
+	var snake *Snake
+	if e.Snake < len(g.Snakes) {
+		stats, snake = g.stats.Snakes[e.Snake], g.Snakes[e.Snake]
+	}
+	switch e.Kind {
+	case ExpectAlive:
+		if stats.dead {
+			return e.failure("died of " + stats.CauseOfDeath)
+		}
+	case ExpectDead:
+		if !stats.dead {
+			return e.failure("is alive")
+		}
+		if e.Cause != "" && e.Cause != stats.CauseOfDeath {
+			return e.failure("died of " + stats.CauseOfDeath)
+		}
+	case ExpectOver:
+		if !g.IsOver {
+			return e.failure("the game is running")
+		}
+	case ExpectRunning:
+		if g.IsOver {
+			return e.failure("the game is over")
+		}
+	case ExpectHead:
+		if head := snake.SnakeParts[0].Coordinate; head != e.Cells[0] {
+			return e.failure(fmt.Sprintf("head is at %v,%v", head.x, head.y))
+		}
+	case ExpectLength:
+		if len(snake.SnakeParts) != e.Value {
+			return e.failure(fmt.Sprintf("length is %v", len(snake.SnakeParts)))
+		}
+	case ExpectScore:
+		if snake.Score != e.Value {
+			return e.failure(fmt.Sprintf("score is %v", snake.Score))
+		}
+	}
+	return ""
+}
+
+func (e Expectation) failure(got string) string {
+	return fmt.Sprintf("expect %v: %v", e, got)
+}
+
+// String returns the expectation the way it is written in a scenario.
+func (e Expectation) String() string {
+	parts := []string{e.Kind}
+	switch e.Kind {
+	case ExpectOver, ExpectRunning:
+		return e.Kind
+	case ExpectDead:
+		if e.Cause != "" {
+			parts = append(parts, string(snakeLetter(e.Snake)), e.Cause)
+			return strings.Join(parts, " ")
+		}
+	case ExpectLength, ExpectScore:
+		parts = append(parts, string(snakeLetter(e.Snake)), strconv.Itoa(e.Value))
+		return strings.Join(parts, " ")
+	case ExpectHead, ExpectAvoid:
+		parts = append(parts, string(snakeLetter(e.Snake)))
+		for _, c := range e.Cells {
+			parts = append(parts, fmt.Sprintf("%v,%v", c.x, c.y))
+		}
+		return strings.Join(parts, " ")
+	}
+	return strings.Join(append(parts, string(snakeLetter(e.Snake))), " ")
+}
+
+// Scenario captures the current snapshot of the game as a scenario without
+// expectations.
+func (g *Game) Scenario() *Scenario {
+	snap := g.Snapshot()
+	sc := &Scenario{
+		Name:    fmt.Sprintf("%v tick %v", g.highScoreMode, snap.Tick),
+		Width:   g.Board.width,
+		Height:  g.Board.height,
+		Snakes:  make([]ScenarioSnake, len(snap.Snakes)),
+		Bonus:   map[Coordinate]bool{},
+		Terrain: map[Coordinate]int{},
+	}
+	for _, c := range g.Board.area {
+		if kind := g.Board.Terrain(c); kind != KindPlain {
+			sc.Terrain[c] = kind
+		}
+	}
+	for i, snake := range snap.Snakes {
+		s := ScenarioSnake{Direction: snake.Direction, Score: snake.Score}
+		for _, sp := range snake.SnakeParts {
+			if sp.Coordinate == newCoordinate(0, 0) {
+				s.Grow++
+			} else {
+				s.Body = append(s.Body, sp.Coordinate)
+			}
+		}
+		if i >= g.PlayerNumber {
+			setting := BotSetting{Type: BotClassic}
+			if j := i - g.PlayerNumber; j < len(g.settings.BotSettings) {
+				setting = g.settings.BotSettings[j]
+			}
+			s.Bot = &setting
+		}
+		sc.Snakes[i] = s
+	}
+	for _, f := range snap.Food {
+		sc.Food = append(sc.Food, f.Coordinates)
+		if f.Type == FoodBonus {
+			sc.Bonus[f.Coordinates] = true
+		}
+	}
+	return sc
+}
+
+// String writes the scenario in its textual representation.
+func (sc *Scenario) String() string {
+	var b strings.Builder
+	if sc.Name != "" {
+		fmt.Fprintf(&b, "name %v\n", sc.Name)
+	}
+	if sc.Seed != 0 {
+		fmt.Fprintf(&b, "seed %v\n", sc.Seed)
+	}
+	if sc.Ticks != 0 {
+		fmt.Fprintf(&b, "ticks %v\n", sc.Ticks)
+	}
+	for i, s := range sc.Snakes {
+		letter := snakeLetter(i)
+		if s.Bot != nil {
+			fmt.Fprintf(&b, "bot %c %v", letter, s.Bot.Type)
+			if s.Bot.Difficulty != "" {
+				fmt.Fprintf(&b, " %v", s.Bot.Difficulty)
+			}
+			b.WriteByte('\n')
+			if s.Bot.Search != "" {
+				fmt.Fprintf(&b, "search %c %v\n", letter, s.Bot.Search)
+			}
+			if s.Bot.Team != "" {
+				fmt.Fprintf(&b, "team %c %v\n", letter, s.Bot.Team)
+			}
+			if s.Bot.Personality != "" {
+				fmt.Fprintf(&b, "personality %c %v\n", letter, s.Bot.Personality)
+			}
+			if s.Bot.Target != "" {
+				fmt.Fprintf(&b, "target %c %v\n", letter, s.Bot.Target)
+			}
+		}
+		fmt.Fprintf(&b, "dir %c %v\n", letter, directionNames[s.Direction])
+		if s.Score != 0 {
+			fmt.Fprintf(&b, "score %c %v\n", letter, s.Score)
+		}
+		if s.Grow != 0 {
+			fmt.Fprintf(&b, "grow %c %v\n", letter, s.Grow)
+		}
+		if len(s.Moves) > 0 {
+			moves := make([]string, len(s.Moves))
+			for j, dir := range s.Moves {
+				moves[j] = "-"
+				if name, ok := directionNames[dir]; ok {
+					moves[j] = name
+				}
+			}
+			fmt.Fprintf(&b, "move %c %v\n", letter, strings.Join(moves, " "))
+		}
+	}
+
+	// Snakes whose body can't be read back from letters are numbered.
+	numbered := map[int]bool{}
+	for i, s := range sc.Snakes {
+		alone := &Scenario{Width: sc.Width, Height: sc.Height, Snakes: []ScenarioSnake{s}}
+		parsed, err := ParseScenario("board\n" + joinRows(alone.board(nil)))
+		numbered[i] = err != nil || !sameBody(parsed.Snakes[0].Body, s.Body)
+	}
+	rows := sc.board(numbered)
+	for _, f := range sc.Food {
+		if r := rows[f.y][f.x]; r != '*' && r != '$' {
+			// the food is under a snake
+			fmt.Fprintf(&b, "food %v,%v", f.x, f.y)
+			if sc.Bonus[f] {
+				b.WriteString(" bonus")
+			}
+			b.WriteByte('\n')
+		}
+	}
+	for _, kind := range []int{KindMud, KindIce, KindGrass} {
+		cells := make([]string, 0)
+		for y, row := range rows {
+			for x, r := range row {
+				c := newCoordinate(x, y)
+				if sc.Terrain[c] == kind && r != KindRunes[kind] {
+					cells = append(cells, fmt.Sprintf("%v,%v", c.x, c.y))
+				}
+			}
+		}
+		if len(cells) > 0 {
+			fmt.Fprintf(&b, "terrain %v %v\n", terrainNames[kind], strings.Join(cells, " "))
+		}
+	}
+	for _, e := range sc.Expect {
+		fmt.Fprintf(&b, "expect %v\n", e)
+	}
+	b.WriteString("board\n")
+	b.WriteString(joinRows(rows))
+	return b.String()
+}
+
+// board draws the board of the scenario, the body parts of the numbered
+// snakes are written as digits.
+func (sc *Scenario) board(numbered map[int]bool) [][]rune {
+	rows := make([][]rune, sc.Height+1)
+	for y := range rows {
+		rows[y] = make([]rune, sc.Width+1)
+		for x := range rows[y] {
+			rows[y][x] = '.'
+			if x == 0 || y == 0 || x == sc.Width || y == sc.Height {
+				rows[y][x] = '#'
+			}
+		}
+	}
+	for c, kind := range sc.Terrain {
+		rows[c.y][c.x] = KindRunes[kind]
+	}
+	for _, f := range sc.Food {
+		rows[f.y][f.x] = '*'
+		if sc.Bonus[f] {
+			rows[f.y][f.x] = '$'
+		}
+	}
+	for i, s := range sc.Snakes {
+		for j, c := range s.Body {
+			r := snakeLetter(i)
+			switch {
+			case j == 0:
+				r = r - 'a' + 'A'
+			case numbered[i]:
+				r = rune('0' + j%10)
+			}
+			rows[c.y][c.x] = r
+		}
+	}
+	return rows
+}
+
+func joinRows(rows [][]rune) string {
+	var b strings.Builder
+	for _, row := range rows {
+		b.WriteString(string(row))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+func sameBody(a, b []Coordinate) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// dumpScenario saves the current tick as a scenario file in the data
+// directory.
+func (g *Game) dumpScenario() {
+	sc := g.Scenario()
+	path, err := saveScenario(sc)
+	if err != nil {
+		g.logger.Error("scenario dump failed", "err", err)
+		return
+	}
+	g.event("scenario saved", "path", path)
+}
+
+func saveScenario(sc *Scenario) (string, error) {
+	dir, err := dataDir()
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, "scenarios")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%v.txt", time.Now().Format("20060102-150405.000")))
+	return path, os.WriteFile(path, []byte(sc.String()), 0o644)
+}
@@ -0,0 +1,113 @@
+package snake
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestScenarios plays every scenario of the scenarios directory.
+func TestScenarios(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("..", "scenarios", "*.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no scenarios found")
+	}
+	for _, path := range paths {
+		path := path
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			t.Parallel()
+			sc, err := LoadScenario(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, failures, err := sc.Run()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, f := range failures {
+				t.Error(f)
+			}
+		})
+	}
+}
+
+// TestScenarioString checks that a scenario reads back from its text, and
+// from the game it plays.
+func TestScenarioString(t *testing.T) {
+	team, err := LoadScenario(filepath.Join("..", "scenarios", "team.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bonus, err := ParseScenario(`food 2,1 bonus
+board
+#######
+#aA..$#
+#..*..#
+#######
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, sc := range []*Scenario{team, bonus} {
+		again, err := ParseScenario(sc.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := again.String(), sc.String(); got != want {
+			t.Errorf("scenario changed when read back:\n%v\nwant\n%v", got, want)
+		}
+		g, err := sc.NewGame()
+		if err != nil {
+			t.Fatal(err)
+		}
+		played := g.Scenario()
+		if got, want := played.board(nil), sc.board(nil); !reflect.DeepEqual(got, want) {
+			t.Errorf("game board:\n%v\nwant\n%v", joinRows(got), joinRows(want))
+		}
+		if !reflect.DeepEqual(played.Bonus, sc.Bonus) {
+			t.Errorf("game bonus food = %v, want %v", played.Bonus, sc.Bonus)
+		}
+	}
+}
+
+// TestRunHeadless drives the event engine of a headless game, which has no
+// screen to draw on.
+func TestRunHeadless(t *testing.T) {
+	sc, err := ParseScenario(`move a right right
+board
+########
+#aA....#
+#......#
+########
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := sc.NewGame()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		g.Run(ctx)
+	}()
+	for _, e := range []Event{DirectionChange{SnakeID: 0, Dir: Right}, Tick{}, Tick{}, ToggleDebug{}, Quit{}} {
+		if !g.Post(ctx, e) {
+			t.Fatal("engine stopped early")
+		}
+	}
+	<-done
+
+	s := g.Snapshot()
+	if head := s.Snakes[0].SnakeParts[0].Coordinate; head != newCoordinate(4, 1) {
+		t.Errorf("head = %v, want 4,1", head)
+	}
+}
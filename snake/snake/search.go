@@ -0,0 +1,297 @@
+package snake
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Search limits of the search bot.
+const (
+	defaultSearchBudget = 100 * time.Millisecond
+	maxMinimaxDepth     = 64
+	mctsTreeDepth       = 8
+	mctsRolloutDepth    = 12
+	mctsExploration     = 1.4
+)
+
+// searchBot simulates several ticks ahead over the moves of every snake. With
+// two snakes it runs an alpha-beta minimax, where the opponent answers each
+// of the bot's moves, deepened until the time budget runs out. With more
+// snakes it runs Monte Carlo tree search over its own moves while the others
+// play random safe moves. A look ahead limits the depth of both searches.
+type searchBot struct {
+	budget       time.Duration
+	deadline     time.Time
+	timeUp       bool
+	rand         *rand.Rand
+	grid         *Grid
+	fill         *floodFill
+	maxDepth     int
+	treeDepth    int
+	rolloutDepth int
+}
+
+func newSearchBot(board *Board, budget time.Duration, level Difficulty, seed int64) *searchBot {
+	if budget <= 0 {
+		budget = defaultSearchBudget
+	}
+	b := &searchBot{
+		budget:       budget,
+		rand:         rand.New(rand.NewSource(seed)),
+		grid:         newBoardGrid(board),
+		fill:         &floodFill{},
+		maxDepth:     maxMinimaxDepth,
+		treeDepth:    mctsTreeDepth,
+		rolloutDepth: mctsRolloutDepth,
+	}
+	if level.LookAhead > 0 {
+		b.maxDepth = level.LookAhead
+		b.rolloutDepth = level.LookAhead
+		if level.LookAhead < b.treeDepth {
+			b.treeDepth = level.LookAhead
+		}
+	}
+	return b
+}
+
+func (b *searchBot) NextDirection(g *Game, snakeNumber int) int {
+	b.deadline = time.Now().Add(b.budget)
+	b.timeUp = false
+	s := g.state(snakeNumber)
+	moves := s.SafeMoves(snakeNumber)
+	if len(moves) == 0 {
+		return -1
+	}
+	if len(moves) == 1 {
+		return moves[0]
+	}
+
+	alive := make([]int, 0)
+	for i, snake := range s.Snakes {
+		if i != snakeNumber && snake.Alive {
+			alive = append(alive, i)
+		}
+	}
+	if len(alive) == 1 {
+		return b.minimax(s, snakeNumber, alive[0], moves)
+	}
+	return b.mcts(s, snakeNumber, moves)
+}
+
+// expired tells if the time budget of the tick is used up.
+func (b *searchBot) expired() bool {
+	if !b.timeUp && time.Now().After(b.deadline) {
+		b.timeUp = true
+	}
+	return b.timeUp
+}
+
+// evaluate scores a state for a snake between 0 and 1 by combining the room
+// its head can reach, its length compared to the longest opponent and its
+// distance to the closest food. A dead snake scores 0.
+func (b *searchBot) evaluate(s *State, snakeNumber int) float64 {
+	me := s.Snakes[snakeNumber]
+	if !me.Alive {
+		return 0
+	}
+	length := len(me.Body) + me.grow
+
+	longest := 0
+	for i, snake := range s.Snakes {
+		if i != snakeNumber && snake.Alive && len(snake.Body)+snake.grow > longest {
+			longest = len(snake.Body) + snake.grow
+		}
+	}
+	lengthScore := float64(length) / float64(length+longest)
+
+	s.fillGrid(b.grid)
+	area, _ := b.fill.count(b.grid, me.Body[0], me.Body[0], 2*length)
+	areaScore := math.Min(float64(area)/float64(2*length), 1)
+
+	foodScore := 0.0
+	for _, f := range s.Food {
+		score := 1 - float64(manhattan(me.Body[0], f))/float64(s.Width+s.Height)
+		if score > foodScore {
+			foodScore = score
+		}
+	}
+
+	return 0.5*areaScore + 0.3*lengthScore + 0.2*foodScore
+}
+
+// minimax searches the moves of the bot against one opponent with iterative
+// deepening and returns the best move of the deepest finished search.
+func (b *searchBot) minimax(s *State, me, opp int, moves []int) int {
+	best := moves[0]
+	for depth := 1; depth <= b.maxDepth; depth++ {
+		move, bestValue := -1, math.Inf(-1)
+		for _, m := range moves {
+			v := b.minValue(s, me, opp, m, depth, bestValue, math.Inf(1))
+			if b.expired() {
+				return best
+			}
+			if v > bestValue {
+				move, bestValue = m, v
+			}
+		}
+		best = move
+		if bestValue <= -1 || bestValue >= 1 {
+			// the outcome is decided, looking deeper won't change it
+			return best
+		}
+	}
+	return best
+}
+
+// maxValue is the value of a state where the bot picks its move.
+func (b *searchBot) maxValue(s *State, me, opp int, depth int, alpha, beta float64) float64 {
+	if v, done := b.terminal(s, me, opp, depth); done {
+		return v
+	}
+	moves := s.SafeMoves(me)
+	if len(moves) == 0 {
+		return -1
+	}
+	value := math.Inf(-1)
+	for _, m := range moves {
+		value = math.Max(value, b.minValue(s, me, opp, m, depth, alpha, beta))
+		if value >= beta || b.expired() {
+			return value
+		}
+		alpha = math.Max(alpha, value)
+	}
+	return value
+}
+
+// minValue is the value of the bot's move when the opponent answers it.
+func (b *searchBot) minValue(s *State, me, opp int, move int, depth int, alpha, beta float64) float64 {
+	replies := s.SafeMoves(opp)
+	if len(replies) == 0 {
+		replies = []int{s.Snakes[opp].Direction}
+	}
+	dirs := make([]int, len(s.Snakes))
+	value := math.Inf(1)
+	for _, r := range replies {
+		dirs[me], dirs[opp] = move, r
+		child := s.Clone()
+		child.Step(dirs)
+		value = math.Min(value, b.maxValue(child, me, opp, depth-1, alpha, beta))
+		if value <= alpha || b.expired() {
+			return value
+		}
+		beta = math.Min(beta, value)
+	}
+	return value
+}
+
+// terminal returns the value of a state that ends the search: -1 if the bot
+// died, 1 if only the opponent did and the heuristic difference at the depth
+// limit.
+func (b *searchBot) terminal(s *State, me, opp int, depth int) (float64, bool) {
+	switch {
+	case !s.Snakes[me].Alive:
+		return -1, true
+	case !s.Snakes[opp].Alive:
+		return 1, true
+	case depth <= 0:
+		return b.evaluate(s, me) - b.evaluate(s, opp), true
+	}
+	return 0, false
+}
+
+// mctsNode is a node of the search tree over the bot's own moves.
+type mctsNode struct {
+	parent   *mctsNode
+	children []*mctsNode
+	untried  []int
+	move     int
+	visits   int
+	value    float64
+}
+
+// mcts runs Monte Carlo tree search until the time budget is used up and
+// returns the most visited move.
+func (b *searchBot) mcts(root *State, me int, moves []int) int {
+	tree := &mctsNode{untried: append([]int{}, moves...), move: -1}
+	for !b.expired() {
+		s := root.Clone()
+		node := tree
+		depth := 0
+
+		// selection
+		for len(node.untried) == 0 && len(node.children) > 0 && s.Snakes[me].Alive {
+			node = node.bestChild()
+			b.step(s, me, node.move)
+			depth++
+		}
+		// expansion
+		if len(node.untried) > 0 && s.Snakes[me].Alive && depth < b.treeDepth {
+			i := b.rand.Intn(len(node.untried))
+			move := node.untried[i]
+			node.untried = append(node.untried[:i], node.untried[i+1:]...)
+			b.step(s, me, move)
+			child := &mctsNode{parent: node, move: move}
+			if s.Snakes[me].Alive {
+				child.untried = s.SafeMoves(me)
+			}
+			node.children = append(node.children, child)
+			node = child
+		}
+		// rollout
+		for i := 0; i < b.rolloutDepth && s.Snakes[me].Alive; i++ {
+			b.step(s, me, b.randomMove(s, me))
+		}
+		// backpropagation
+		value := b.evaluate(s, me)
+		for ; node != nil; node = node.parent {
+			node.visits++
+			node.value += value
+		}
+	}
+
+	best, visits := moves[0], -1
+	for _, child := range tree.children {
+		if child.visits > visits {
+			best, visits = child.move, child.visits
+		}
+	}
+	return best
+}
+
+// bestChild selects a child with the UCB1 formula.
+func (n *mctsNode) bestChild() *mctsNode {
+	var best *mctsNode
+	bestScore := math.Inf(-1)
+	for _, child := range n.children {
+		score := child.value/float64(child.visits) +
+			mctsExploration*math.Sqrt(math.Log(float64(n.visits))/float64(child.visits))
+		if score > bestScore {
+			best, bestScore = child, score
+		}
+	}
+	return best
+}
+
+// step moves the bot with its move and every other snake randomly.
+func (b *searchBot) step(s *State, me int, move int) {
+	dirs := make([]int, len(s.Snakes))
+	for i := range dirs {
+		if i == me {
+			dirs[i] = move
+		} else if s.Snakes[i].Alive {
+			dirs[i] = b.randomMove(s, i)
+		}
+	}
+	s.Step(dirs)
+}
+
+// randomMove returns a random safe move of a snake, or keeps its direction if
+// it has none.
+func (b *searchBot) randomMove(s *State, snakeNumber int) int {
+	moves := s.SafeMoves(snakeNumber)
+	if len(moves) == 0 {
+		return s.Snakes[snakeNumber].Direction
+	}
+	return moves[b.rand.Intn(len(moves))]
+}
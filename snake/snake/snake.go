@@ -1,9 +1,6 @@
 package snake
 
-import (
-	"errors"
-	"log"
-)
+import "errors"
 
 type SnakePart struct {
 	Coordinate Coordinate
@@ -15,64 +12,25 @@ type Snake struct {
 	Direction  int
 	Score      int
 	IsBot      bool
+	// skip is the number of moves the snake sits out in mud.
+	skip int
 }
 
-func (s *Snake) canMove(board *Board, snakes []*Snake) bool {
-	nextHeadPosition, err := s.nextHeadPosition()
-
-	if err != nil {
-		log.Fatal(err.Error())
-	}
-
-	for _, snake := range snakes {
-		for _, position := range snake.SnakeParts {
-			if nextHeadPosition == position.Coordinate {
-				return false
-			}
-		}
-	}
-
-	switch s.Direction {
-	case Up:
-		return nextHeadPosition.y > 0
-	case Left:
-		return nextHeadPosition.x > 0
-	case Right:
-		return nextHeadPosition.x < board.width
-	case Down:
-		return nextHeadPosition.y < board.height
-	}
-
-	return true
-}
+// Causes of death reported by collision.
+const (
+	CauseWall = "wall"
+	CauseSelf = "self"
+)
 
-func (s *Snake) canMoveBot(board *Board, snakes []*Snake, newDir int) bool {
+// canMoveBot tells if the snake can move in a direction on a grid of the
+// board as a bot sees it, where the walls and snakes are blockers.
+func (s *Snake) canMoveBot(gr *Grid, newDir int) bool {
 	nextHeadPosition, err := s.nextHeadPositionBot(newDir)
 
 	if err != nil {
-		log.Fatal(err.Error())
+		return false
 	}
-
-	for _, snake := range snakes {
-		for _, position := range snake.SnakeParts {
-			if nextHeadPosition == position.Coordinate {
-				return false
-			}
-		}
-	}
-
-	switch newDir {
-	case Up:
-		return nextHeadPosition.y > 0
-	case Left:
-		return nextHeadPosition.x > 0
-	case Right:
-		return nextHeadPosition.x < board.width
-	case Down:
-		return nextHeadPosition.y < board.height
-	}
-
-	return true
+	return gr.Kind(nextHeadPosition) != KindBlocker
 }
 
 func (s *Snake) nextHeadPosition() (Coordinate, error) {
@@ -128,15 +86,6 @@ func testSnakeMove(newDir int, snakeTest Snake) (Coordinate, error) {
 	return head, err
 }
 
-func (s *Snake) contains(coordinate Coordinate) bool {
-	for _, body := range (*s).SnakeParts {
-		if coordinate == body.Coordinate {
-			return true
-		}
-	}
-	return false
-}
-
 func (s *Snake) CanEat(food *Food) bool {
 	headPosition := (*s).SnakeParts[0]
 	return headPosition.Coordinate.x == food.Coordinates.x && headPosition.Coordinate.y == food.Coordinates.y
@@ -160,7 +109,6 @@ func (s *Snake) move() {
 		if i == 0 {
 			coordinates, err = s.nextHeadPosition()
 			if err != nil {
-				log.Fatalln(err.Error())
 				return
 			}
 		} else {
@@ -215,5 +163,16 @@ func (s *Snake) copySnake() Snake {
 	snake.SnakeParts = body
 	snake.Direction = s.Direction
 	snake.Score = s.Score
+	snake.IsBot = s.IsBot
+	snake.skip = s.skip
 	return snake
 }
+
+// body returns the coordinates of the snake parts, head first.
+func (s *Snake) body() []Coordinate {
+	body := make([]Coordinate, len(s.SnakeParts))
+	for i, sp := range s.SnakeParts {
+		body[i] = sp.Coordinate
+	}
+	return body
+}
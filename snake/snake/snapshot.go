@@ -0,0 +1,112 @@
+package snake
+
+// A Snapshot is an immutable copy of the game. The engine publishes one after
+// every event it handles, the renderers and the bots only read snapshots and
+// never the game the engine changes. Nothing in a snapshot may be modified.
+type Snapshot struct {
+	Tick    int
+	Started bool
+	Over    bool
+	Paused  bool
+	// Lost is the snake that lost the game once it is over.
+	Lost int
+
+	Board  *Board
+	Snakes []*Snake
+	Food   []Food
+
+	BotPaths    map[int][]Coordinate
+	BotSearches map[int]SearchTrace
+	ShowHeatmap bool
+	ShowDebug   bool
+	Stats       []SnakeStats
+	Events      []string
+
+	HighScores      []HighScore
+	HighScoreStatus string
+	StatsStatus     string
+	// NameEntry is the player entering a name for the high score table, -1
+	// if nobody is.
+	NameEntry int
+	NameInput string
+}
+
+// Snapshot returns the last published snapshot of the game.
+func (g *Game) Snapshot() *Snapshot {
+	return g.snapshot.Load()
+}
+
+// publish copies the game into a new snapshot and makes it the current one.
+func (g *Game) publish() *Snapshot {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	s := &Snapshot{
+		Tick:            g.stats.Ticks,
+		Started:         g.IsStart,
+		Over:            g.IsOver,
+		Paused:          g.IsPaused,
+		Lost:            g.whoLost,
+		Board:           g.Board,
+		Snakes:          make([]*Snake, len(g.Snakes)),
+		Food:            append([]Food{}, g.Food...),
+		BotPaths:        make(map[int][]Coordinate, len(g.BotPaths)),
+		BotSearches:     make(map[int]SearchTrace, len(g.BotSearches)),
+		ShowHeatmap:     g.ShowHeatmap,
+		ShowDebug:       g.ShowDebug,
+		Stats:           make([]SnakeStats, len(g.stats.Snakes)),
+		Events:          append([]string{}, g.events...),
+		HighScores:      append([]HighScore{}, g.HighScores...),
+		HighScoreStatus: g.highScoreStatus,
+		StatsStatus:     g.statsStatus,
+		NameEntry:       -1,
+		NameInput:       g.nameInput,
+	}
+	for i, snake := range g.Snakes {
+		copied := snake.copySnake()
+		s.Snakes[i] = &copied
+	}
+	// paths and traces are replaced as a whole, never changed in place
+	for i, path := range g.BotPaths {
+		s.BotPaths[i] = path
+	}
+	for i, trace := range g.BotSearches {
+		s.BotSearches[i] = trace
+	}
+	for i, st := range g.stats.Snakes {
+		st.FoodEaten = make(map[string]int, len(st.FoodEaten))
+		for t, n := range g.stats.Snakes[i].FoodEaten {
+			st.FoodEaten[t] = n
+		}
+		s.Stats[i] = st
+	}
+	if len(g.nameEntries) > 0 {
+		s.NameEntry = g.nameEntries[0]
+	}
+	g.snapshot.Store(s)
+	return s
+}
+
+// botView makes a game of a snapshot for the bots to decide on. The bots store
+// their paths and searches in the view, they get to the game with their
+// decision.
+func (g *Game) botView(s *Snapshot) *Game {
+	return &Game{
+		Board:        s.Board,
+		Speed:        g.Speed,
+		Snakes:       s.Snakes,
+		Food:         s.Food,
+		PlayerNumber: g.PlayerNumber,
+		FoodNumber:   g.FoodNumber,
+		BotNumber:    g.BotNumber,
+		IsStart:      s.Started,
+		IsOver:       s.Over,
+		IsPaused:     s.Paused,
+		BotPaths:     make(map[int][]Coordinate),
+		BotSearches:  make(map[int]SearchTrace),
+		ShowHeatmap:  s.ShowHeatmap,
+		settings:     g.settings,
+		logger:       g.logger,
+		headless:     true,
+		botGrid:      s.botGrid(),
+	}
+}
@@ -0,0 +1,175 @@
+package snake
+
+// spaceTimeSearch is an A* over cells and moves: a snake body cell is only
+// blocked until the move it becomes free in, see Grid.Frees. A snake can't
+// wait, but it can walk a detour while a coil of a body moves out of its way,
+// so a cell may be on the path more than once. The body of the snake follows
+// its head along the path, a cell it left is only entered again once the
+// body moved out of it. The body is never longer than the last move a cell
+// becomes free in, so that many steps back on the path are checked.
+//
+// Once every body has moved away the moves don't matter anymore, so the
+// states of later moves are merged into the one of the last move a body
+// blocks a cell.
+type spaceTimeSearch struct {
+	costs []int
+	nodes []timedNode
+	// states maps a cell and a move to its node.
+	states map[int64]int32
+	open   openHeap
+	path   []Coordinate
+	grid   *Grid
+	// last is the last move in which a body cell becomes free or a cell is
+	// reserved.
+	last int
+	// reserved are the cells other bots of a team will be on, nil without a
+	// team.
+	reserved *reservationTable
+}
+
+// timedNode is a cell reached in a move.
+type timedNode struct {
+	cell   int32
+	move   int32
+	g      int
+	h      int
+	parent int32
+	closed bool
+}
+
+// spaceTimeLimit bounds the states of a search by this many per cell.
+const spaceTimeLimit = 4
+
+func newSpaceTimeSearch() *spaceTimeSearch {
+	return &spaceTimeSearch{costs: NewGridPather().costs}
+}
+
+func (s *spaceTimeSearch) Path(gr *Grid, from, to Coordinate) ([]Coordinate, int, bool) {
+	s.grid = gr
+	s.nodes = s.nodes[:0]
+	s.open = s.open[:0]
+	s.states = make(map[int64]int32)
+	if !gr.Inside(from) || !gr.Inside(to) {
+		return nil, 0, false
+	}
+	s.last = 0
+	for _, free := range gr.Frees {
+		if free > s.last {
+			s.last = free
+		}
+	}
+	if s.reserved != nil && s.reserved.last > s.last {
+		s.last = s.reserved.last
+	}
+
+	goal := gr.Index(to)
+	s.reach(gr, gr.Index(from), 0, 0, -1, to)
+	for len(s.open) > 0 {
+		e := s.open.pop()
+		n := &s.nodes[e.i]
+		if n.closed || e.fCost > n.g+n.h {
+			continue
+		}
+		n.closed = true
+		if int(n.cell) == goal {
+			return s.reconstruct(e.i), n.g, true
+		}
+		if len(s.nodes) > spaceTimeLimit*len(gr.Kinds) {
+			break
+		}
+		cell, move, g := int(n.cell), int(n.move)+1, n.g
+		for dir := Up; dir <= Down; dir++ {
+			j, ok := neighborCell(gr, cell, dir)
+			if !ok || !s.enterable(gr, j, move) || s.onTrail(e.i, j) {
+				continue
+			}
+			s.reach(gr, j, move, g+s.cost(gr.Kinds[j]), e.i, to)
+		}
+	}
+	return nil, 0, false
+}
+
+// enterable tells if a cell can be entered in a move.
+func (s *spaceTimeSearch) enterable(gr *Grid, i int, move int) bool {
+	if s.reserved != nil && s.reserved.taken(i, move) {
+		return false
+	}
+	if gr.Walkable(i) {
+		return true
+	}
+	return gr.Frees != nil && gr.Frees[i] > 0 && move >= gr.Frees[i]
+}
+
+// onTrail tells if the body of the snake is on a cell when it reached a node,
+// the cell is one of the last steps on the path to it.
+func (s *spaceTimeSearch) onTrail(i int32, cell int) bool {
+	for k := 0; k < s.last && i != -1; k++ {
+		if int(s.nodes[i].cell) == cell {
+			return true
+		}
+		i = s.nodes[i].parent
+	}
+	return false
+}
+
+// reach records a cheaper way to a cell in a move and queues it. Moves after
+// the last one a body blocks a cell in count as that one.
+func (s *spaceTimeSearch) reach(gr *Grid, cell int, move int, g int, parent int32, to Coordinate) {
+	if move > s.last {
+		move = s.last
+	}
+	key := int64(cell)*int64(s.last+1) + int64(move)
+	i, ok := s.states[key]
+	if ok {
+		n := &s.nodes[i]
+		if n.closed || g >= n.g {
+			return
+		}
+		n.g, n.parent = g, parent
+	} else {
+		i = int32(len(s.nodes))
+		s.states[key] = i
+		s.nodes = append(s.nodes, timedNode{
+			cell:   int32(cell),
+			move:   int32(move),
+			g:      g,
+			h:      manhattan(gr.Coordinate(cell), to),
+			parent: parent,
+		})
+	}
+	n := &s.nodes[i]
+	s.open.push(openCell{i: i, fCost: n.g + n.h, hCost: n.h})
+}
+
+// reconstruct follows the parents of a node back to the start and returns the
+// path in forward order.
+func (s *spaceTimeSearch) reconstruct(i int32) []Coordinate {
+	s.path = s.path[:0]
+	for ; i != -1; i = s.nodes[i].parent {
+		s.path = append(s.path, s.grid.Coordinate(int(s.nodes[i].cell)))
+	}
+	for a, b := 0, len(s.path)-1; a < b; a, b = a+1, b-1 {
+		s.path[a], s.path[b] = s.path[b], s.path[a]
+	}
+	return s.path
+}
+
+func (s *spaceTimeSearch) cost(kind int) int {
+	if kind < len(s.costs) && s.costs[kind] > 0 {
+		return s.costs[kind]
+	}
+	return 1
+}
+
+func (s *spaceTimeSearch) Trace() SearchTrace {
+	trace := SearchTrace{Expanded: make([]Coordinate, 0)}
+	if s.grid == nil {
+		return trace
+	}
+	for _, n := range s.nodes {
+		if n.closed {
+			trace.Expanded = append(trace.Expanded, s.grid.Coordinate(int(n.cell)))
+		}
+	}
+	return trace
+}
@@ -0,0 +1,120 @@
+package snake
+
+import "testing"
+
+// textGrid builds a grid from rows of text: # is a blocker, % mud, = ice,
+// F the start and T the goal. Everything else is plain.
+func textGrid(rows ...string) (*Grid, Coordinate, Coordinate) {
+	gr := NewGrid(len(rows[0]), len(rows))
+	var from, to Coordinate
+	for y, row := range rows {
+		for x, r := range row {
+			c := newCoordinate(x, y)
+			switch r {
+			case '#':
+				gr.Set(c, KindBlocker)
+			case '%':
+				gr.Set(c, KindMud)
+			case '=':
+				gr.Set(c, KindIce)
+			case 'F':
+				gr.Set(c, KindFrom)
+				from = c
+			case 'T':
+				gr.Set(c, KindTo)
+				to = c
+			}
+		}
+	}
+	return gr, from, to
+}
+
+// checkPath fails the test if a path doesn't lead from a cell to another in
+// single steps over walkable cells, or if its cost is not the distance.
+func checkPath(t *testing.T, gr *Grid, path []Coordinate, from, to Coordinate, distance int) {
+	t.Helper()
+	if len(path) == 0 || path[0] != from || path[len(path)-1] != to {
+		t.Fatalf("path %v doesn't lead from %v to %v", path, from, to)
+	}
+	costs := NewGridPather()
+	cost := 0
+	for i := 1; i < len(path); i++ {
+		if manhattan(path[i-1], path[i]) != 1 || gr.Kind(path[i]) == KindBlocker {
+			t.Fatalf("path %v has an invalid step to %v", path, path[i])
+		}
+		cost += costs.cost(gr.Kind(path[i]))
+	}
+	if cost != distance {
+		t.Fatalf("path %v costs %v, distance is %v", path, cost, distance)
+	}
+}
+
+func TestSpaceTimeSearch(t *testing.T) {
+	corridor := []string{
+		"#######",
+		"#F.#.T#",
+		"#######",
+	}
+	detour := []string{
+		"#######",
+		"#F.#.T#",
+		"#.....#",
+		"#######",
+	}
+	body := newCoordinate(3, 1)
+	tests := []struct {
+		name string
+		rows []string
+		// frees is the move the body cell becomes free in, 0 for a wall.
+		frees int
+		// reserved is a move the body cell is reserved in, 0 for none.
+		reserved int
+		found    bool
+		distance int
+	}{
+		{"open", []string{
+			"#######",
+			"#F...T#",
+			"#######",
+		}, 0, 0, true, 4},
+		{"wall", corridor, 0, 0, false, 0},
+		{"body frees in time", corridor, 2, 0, true, 4},
+		{"body frees too late", corridor, 3, 0, false, 0},
+		{"around a body", detour, 10, 0, true, 6},
+		{"reserved when passing", corridor, 1, 2, false, 0},
+		{"reserved later", corridor, 1, 3, true, 4},
+		{"around a reserved cell", detour, 1, 2, true, 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gr, from, to := textGrid(tt.rows...)
+			s := newSpaceTimeSearch()
+			if tt.frees > 0 {
+				gr.Frees = make([]int, len(gr.Kinds))
+				gr.Frees[gr.Index(body)] = tt.frees
+			}
+			if tt.reserved > 0 {
+				s.reserved = newReservationTable()
+				s.reserved.reserve(gr.Index(body), tt.reserved, tt.reserved)
+			}
+			path, distance, found := s.Path(gr, from, to)
+			if found != tt.found {
+				t.Fatalf("found = %v, want %v", found, tt.found)
+			}
+			if !found {
+				return
+			}
+			if distance != tt.distance {
+				t.Errorf("distance = %v, want %v", distance, tt.distance)
+			}
+			for move, c := range path {
+				if c == body && (move < tt.frees || move == tt.reserved) {
+					t.Fatalf("path %v enters the body cell in move %v", path, move)
+				}
+			}
+			if tt.frees == 0 {
+				checkPath(t, gr, path, from, to, distance)
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package snake
+
+// State is a compact copy of a game that can be copied and simulated cheaply,
+// without the screen or the game lock. Search bots step it many times per
+// tick to look ahead.
+type State struct {
+	// Width and Height are the size of the board, the walls are at 0 and at
+	// Width and Height like on the Board.
+	Width, Height int
+	Snakes        []SnakeState
+	Food          []Coordinate
+	// Terrain is the tile kind of every cell like on the Board, nil if the
+	// board is plain. It is shared between copies and never changes.
+	Terrain []int
+}
+
+// SnakeState is a snake in a State.
+type SnakeState struct {
+	Body      []Coordinate
+	Direction int
+	Score     int
+	Alive     bool
+	// grow is the number of moves the tail stays in place.
+	grow int
+	// skip is the number of moves the snake sits out in mud.
+	skip int
+}
+
+// state copies the current game into a State as seen by the bot of a snake.
+// Snakes hidden from it in grass are left out as dead ones.
+func (g *Game) state(viewer int) *State {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	s := &State{
+		Width:   g.Board.width,
+		Height:  g.Board.height,
+		Snakes:  make([]SnakeState, len(g.Snakes)),
+		Food:    make([]Coordinate, len(g.Food)),
+		Terrain: g.Board.terrain,
+	}
+	for i, snake := range g.Snakes {
+		copied := snake.copySnake()
+		body := copied.body()
+		grow := 0
+		if isGrowing(body) {
+			// the grown part is not placed yet
+			body = body[:len(body)-1]
+			grow = 1
+		}
+		s.Snakes[i] = SnakeState{
+			Body:      body,
+			Direction: copied.Direction,
+			Score:     copied.Score,
+			Alive:     i == viewer || !g.hidden(snake),
+			grow:      grow,
+			skip:      snake.skip,
+		}
+	}
+	for i, f := range g.Food {
+		s.Food[i] = f.Coordinates
+	}
+	return s
+}
+
+// Clone returns a deep copy of the state.
+func (s *State) Clone() *State {
+	c := &State{
+		Width:   s.Width,
+		Height:  s.Height,
+		Snakes:  make([]SnakeState, len(s.Snakes)),
+		Food:    append([]Coordinate{}, s.Food...),
+		Terrain: s.Terrain,
+	}
+	for i, snake := range s.Snakes {
+		c.Snakes[i] = snake
+		c.Snakes[i].Body = append(make([]Coordinate, 0, len(snake.Body)+1), snake.Body...)
+	}
+	return c
+}
+
+// Step moves every living snake one tick with the given directions, the same
+// way the game does: the snakes move one after the other, a snake that would
+// hit a wall or a snake dies, and a snake that reaches food eats it. Eaten
+// food is not replaced, as its new place is random. A negative direction, a
+// turn back or a turn on ice keeps the current direction, and a snake in mud
+// sits out its move.
+func (s *State) Step(dirs []int) {
+	for i := range s.Snakes {
+		snake := &s.Snakes[i]
+		if !snake.Alive {
+			continue
+		}
+		if snake.skip > 0 {
+			snake.skip--
+			continue
+		}
+		turn := s.Kind(snake.Body[0]) != KindIce
+		if turn && i < len(dirs) && dirs[i] >= Up && dirs[i] <= Down && dirs[i] != opposite(snake.Direction) {
+			snake.Direction = dirs[i]
+		}
+		next := snake.Body[0].step(snake.Direction)
+		if s.Blocked(next) {
+			snake.Alive = false
+			continue
+		}
+		if snake.grow > 0 {
+			snake.Body = append(snake.Body, Coordinate{})
+			snake.grow--
+		}
+		copy(snake.Body[1:], snake.Body[:len(snake.Body)-1])
+		snake.Body[0] = next
+		if s.Kind(next) == KindMud {
+			snake.skip = mudSkip
+		}
+		for j, f := range s.Food {
+			if f == next {
+				snake.Score++
+				snake.grow++
+				s.Food = append(s.Food[:j], s.Food[j+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// Blocked tells if a coordinate is a wall or part of a living snake.
+func (s *State) Blocked(c Coordinate) bool {
+	if c.x <= 0 || c.y <= 0 || c.x >= s.Width || c.y >= s.Height {
+		return true
+	}
+	for _, snake := range s.Snakes {
+		if !snake.Alive {
+			continue
+		}
+		for _, part := range snake.Body {
+			if part == c {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Kind returns the terrain kind of a cell.
+func (s *State) Kind(c Coordinate) int {
+	if s.Terrain == nil || c.x <= 0 || c.y <= 0 || c.x >= s.Width || c.y >= s.Height {
+		return KindPlain
+	}
+	return s.Terrain[c.y*(s.Width+1)+c.x]
+}
+
+// SafeMoves returns the directions a snake can take without dying right away.
+// A snake that can't turn on ice or sits in mud only has its direction.
+func (s *State) SafeMoves(snakeNumber int) []int {
+	snake := s.Snakes[snakeNumber]
+	moves := make([]int, 0, 3)
+	if snake.skip > 0 {
+		return append(moves, snake.Direction)
+	}
+	for _, dir := range []int{Up, Left, Right, Down} {
+		if s.Kind(snake.Body[0]) == KindIce && dir != snake.Direction {
+			continue
+		}
+		if dir != opposite(snake.Direction) && !s.Blocked(snake.Body[0].step(dir)) {
+			moves = append(moves, dir)
+		}
+	}
+	return moves
+}
+
+// fillGrid draws the state into a grid: the terrain is kept, food is a goal
+// and every living snake part is a blocker.
+func (s *State) fillGrid(gr *Grid) {
+	gr.Reset()
+	if len(s.Terrain) == len(gr.Kinds) {
+		for i, kind := range s.Terrain {
+			if kind != KindPlain {
+				gr.Kinds[i] = kind
+			}
+		}
+	}
+	for _, f := range s.Food {
+		gr.Set(f, KindTo)
+	}
+	for _, snake := range s.Snakes {
+		if !snake.Alive {
+			continue
+		}
+		for _, part := range snake.Body {
+			gr.Set(part, KindBlocker)
+		}
+	}
+}
+
+// opposite returns the direction that turns back.
+func opposite(dir int) int {
+	switch dir {
+	case Up:
+		return Down
+	case Down:
+		return Up
+	case Left:
+		return Right
+	case Right:
+		return Left
+	}
+	return -1
+}
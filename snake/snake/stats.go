@@ -0,0 +1,205 @@
+package snake
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SnakeStats are the statistics of one snake in a game.
+type SnakeStats struct {
+	Snake          int            `json:"snake"`
+	IsBot          bool           `json:"isBot"`
+	Score          int            `json:"score"`
+	TicksSurvived  int            `json:"ticksSurvived"`
+	FoodEaten      map[string]int `json:"foodEaten"`
+	MaxLength      int            `json:"maxLength"`
+	Turns          int            `json:"turns"`
+	CauseOfDeath   string         `json:"causeOfDeath,omitempty"`
+	AvgPlanningMs  float64        `json:"avgBotPlanningMs,omitempty"`
+	Timeouts       int            `json:"botTimeouts,omitempty"`
+	planningTime   time.Duration
+	lastPlanning   time.Duration
+	planningRounds int
+	dead           bool
+}
+
+// GameStats are the statistics of a whole game.
+type GameStats struct {
+	Mode    string       `json:"mode"`
+	Started time.Time    `json:"started"`
+	Ended   time.Time    `json:"ended"`
+	Ticks   int          `json:"ticks"`
+	Snakes  []SnakeStats `json:"snakes"`
+}
+
+// newGameStats starts the statistics of a game with the current snakes.
+func newGameStats(mode string, snakes []*Snake) *GameStats {
+	stats := &GameStats{
+		Mode:    mode,
+		Started: time.Now(),
+		Snakes:  make([]SnakeStats, len(snakes)),
+	}
+	for i, s := range snakes {
+		stats.Snakes[i] = SnakeStats{
+			Snake:     i + 1,
+			IsBot:     s.IsBot,
+			FoodEaten: map[string]int{},
+			MaxLength: len(s.SnakeParts),
+		}
+	}
+	return stats
+}
+
+// recordTick counts a tick for every snake that is still alive.
+func (g *Game) recordTick() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.stats.Ticks++
+	for i, s := range g.Snakes {
+		st := &g.stats.Snakes[i]
+		if st.dead {
+			continue
+		}
+		st.TicksSurvived++
+		if len(s.SnakeParts) > st.MaxLength {
+			st.MaxLength = len(s.SnakeParts)
+		}
+	}
+}
+
+func (g *Game) recordFood(i int, food Food) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.stats.Snakes[i].FoodEaten[food.Type]++
+}
+
+func (g *Game) recordDeath(i int, cause string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.stats.Snakes[i].dead = true
+	g.stats.Snakes[i].CauseOfDeath = cause
+}
+
+// recordTurn counts a direction change. Must hold g.mu.
+func (g *Game) recordTurn(i int) {
+	g.stats.Snakes[i].Turns++
+}
+
+// recordTimeout counts a decision deadline a bot missed.
+func (g *Game) recordTimeout(i int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.stats.Snakes[i].Timeouts++
+}
+
+func (g *Game) recordPlanning(i int, d time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.stats.Snakes[i].planningTime += d
+	g.stats.Snakes[i].lastPlanning = d
+	g.stats.Snakes[i].planningRounds++
+}
+
+// finishStats closes the statistics of the game and exports them.
+func (g *Game) finishStats() {
+	g.mu.Lock()
+	stats := *g.stats
+	stats.Ended = time.Now()
+	stats.Snakes = make([]SnakeStats, len(g.stats.Snakes))
+	for i, st := range g.stats.Snakes {
+		st.Score = g.Snakes[i].Score
+		if st.planningRounds > 0 {
+			st.AvgPlanningMs = float64(st.planningTime.Microseconds()) / float64(st.planningRounds) / 1000
+		}
+		stats.Snakes[i] = st
+	}
+	headless := g.headless
+	g.mu.Unlock()
+	if headless {
+		return
+	}
+
+	path, err := exportStats(&stats)
+	if err != nil {
+		g.logger.Error("stats export failed", "err", err)
+	} else {
+		g.logger.Info("stats exported", "path", path)
+	}
+	g.mu.Lock()
+	if err != nil {
+		g.statsStatus = err.Error()
+	} else {
+		g.statsStatus = "Stats saved to " + path + ".{json,csv}"
+	}
+	g.mu.Unlock()
+}
+
+// exportStats writes the statistics as JSON and CSV into the data directory
+// and returns the path of the files without extension.
+func exportStats(stats *GameStats) (string, error) {
+	dir, err := dataDir()
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, "stats")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	base := filepath.Join(dir, fmt.Sprintf("%v-%v", stats.Mode, stats.Started.Format("20060102-150405")))
+
+	data, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(base+".json", data, 0o644); err != nil {
+		return "", err
+	}
+
+	f, err := os.Create(base + ".csv")
+	if err != nil {
+		return "", err
+	}
+	w := csv.NewWriter(f)
+	w.Write([]string{"snake", "bot", "score", "ticks_survived", "max_length", "turns", "cause_of_death", "avg_bot_planning_ms", "bot_timeouts", "food_eaten"})
+	for _, st := range stats.Snakes {
+		w.Write([]string{
+			strconv.Itoa(st.Snake),
+			strconv.FormatBool(st.IsBot),
+			strconv.Itoa(st.Score),
+			strconv.Itoa(st.TicksSurvived),
+			strconv.Itoa(st.MaxLength),
+			strconv.Itoa(st.Turns),
+			st.CauseOfDeath,
+			strconv.FormatFloat(st.AvgPlanningMs, 'f', 3, 64),
+			strconv.Itoa(st.Timeouts),
+			formatFoodEaten(st.FoodEaten),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		f.Close()
+		return "", err
+	}
+	return base, f.Close()
+}
+
+// formatFoodEaten formats the eaten food as "type:count" pairs.
+func formatFoodEaten(eaten map[string]int) string {
+	types := make([]string, 0, len(eaten))
+	for t := range eaten {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	pairs := make([]string, len(types))
+	for i, t := range types {
+		pairs[i] = fmt.Sprintf("%v:%v", t, eaten[t])
+	}
+	return strings.Join(pairs, ";")
+}
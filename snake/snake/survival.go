@@ -0,0 +1,146 @@
+package snake
+
+// survivalBot goes for the food only if it still has room to live after
+// eating it: after the virtual move along the food path its tail has to be
+// reachable or the reachable area has to fit the snake. Otherwise it takes the
+// move with the most reachable room.
+//
+// A food path may wait for snake bodies to move out of the way, see
+// spaceTimeSearch, when there is none without.
+//
+// Without survival checks it takes every food path and falls back to the free
+// move closest to the food. With a look ahead only that many steps of the food
+// path are checked. Its personality weighs the food paths, the room and the
+// moves, see Personality.
+type survivalBot struct {
+	teamwork
+	grid      *Grid
+	work      *Grid
+	pather    GridSearch
+	timed     *spaceTimeSearch
+	fill      *floodFill
+	checks    bool
+	lookAhead int
+	style     Personality
+	targets   *foodTargets
+}
+
+func newSurvivalBot(board *Board, level Difficulty, search GridSearch, style Personality, targets *foodTargets) *survivalBot {
+	return &survivalBot{
+		grid:      newBoardGrid(board),
+		work:      newBoardGrid(board),
+		pather:    search,
+		timed:     newSpaceTimeSearch(),
+		fill:      &floodFill{},
+		checks:    level.SurvivalChecks,
+		lookAhead: level.LookAhead,
+		style:     style,
+		targets:   targets,
+	}
+}
+
+func (b *survivalBot) joinTeam(p *teamPlanner) *foodTargets {
+	b.join(p, b.targets, b.timed)
+	return b.targets
+}
+
+func (b *survivalBot) NextDirection(g *Game, snakeNumber int) int {
+	g.fillGrid(b.grid, g.Snakes[snakeNumber])
+	b.keepOut(g, snakeNumber, b.grid)
+	body, _ := g.snakeBody(snakeNumber)
+	food, hasFood := b.targets.choose(g, snakeNumber, b.grid)
+	return b.decide(g, snakeNumber, body, food, hasFood)
+}
+
+// decide picks the move on the prepared grid of the bot.
+func (b *survivalBot) decide(g *Game, snakeNumber int, body []Coordinate, food Coordinate, hasFood bool) int {
+	head := body[0]
+	if hasFood {
+		path, _, found := b.pather.Path(b.grid, head, food)
+		g.setBotSearch(snakeNumber, b.pather)
+		if !found {
+			path, _, found = b.timed.Path(b.grid, head, food)
+			g.setBotSearch(snakeNumber, b.timed)
+		}
+		if found && len(path) >= 2 && b.style.wantsFood(b.grid, path) {
+			steps, grow := path[1:], 1
+			if b.lookAhead > 0 && len(steps) > b.lookAhead {
+				steps, grow = steps[:b.lookAhead], 0
+			}
+			if !b.checks || b.isSafe(body, steps, grow) {
+				g.setBotPath(snakeNumber, path)
+				return head.directionTo(path[1])
+			}
+		}
+	}
+	g.setBotPath(snakeNumber, nil)
+	return b.roomiestMove(body, food)
+}
+
+// isSafe tells if the snake can still get out after moving along the steps.
+func (b *survivalBot) isSafe(body []Coordinate, steps []Coordinate, grow int) bool {
+	area, tail := b.room(body, steps, grow, len(body)+grow)
+	return tail || b.style.enoughRoom(area, len(body)+grow)
+}
+
+// room moves the snake virtually along the steps and measures the area its
+// head can reach afterwards and if the tail is among it.
+func (b *survivalBot) room(body []Coordinate, steps []Coordinate, grow int, limit int) (int, bool) {
+	newBody := moveBody(body, steps, grow)
+	copy(b.work.Kinds, b.grid.Kinds)
+	for _, c := range body {
+		b.work.Set(c, KindPlain)
+	}
+	// the tail moves away in the next tick, so it is not a blocker
+	for _, c := range newBody[:len(newBody)-1] {
+		b.work.Set(c, KindBlocker)
+	}
+	return b.fill.count(b.work, newBody[0], newBody[len(newBody)-1], limit)
+}
+
+// roomiestMove returns the move after which the snake can reach the most
+// cells, less the penalty of its personality, preferring moves that keep the
+// tail reachable and then the ones closer to the food. It is -1 if every move
+// is blocked.
+func (b *survivalBot) roomiestMove(body []Coordinate, food Coordinate) int {
+	head := body[0]
+	best, bestRoom, bestTail, bestDist := -1, 0.0, false, 0
+	for _, dir := range []int{Up, Left, Right, Down} {
+		next := head.step(dir)
+		if b.grid.Kind(next) == KindBlocker {
+			continue
+		}
+		grow := 0
+		if next == food {
+			grow = 1
+		}
+		area, tail := 0, false
+		if b.checks {
+			area, tail = b.room(body, []Coordinate{next}, grow, 0)
+		}
+		room := float64(area) - b.style.penalty(b.grid, next)
+		dist := manhattan(next, food)
+		better := best == -1 ||
+			(tail && !bestTail) ||
+			(tail == bestTail && room > bestRoom) ||
+			(tail == bestTail && room == bestRoom && dist < bestDist)
+		if better {
+			best, bestRoom, bestTail, bestDist = dir, room, tail, dist
+		}
+	}
+	return best
+}
+
+// moveBody returns the body of a snake after its head moved along the steps
+// and it grew by grow parts.
+func moveBody(body []Coordinate, steps []Coordinate, grow int) []Coordinate {
+	length := len(body) + grow
+	newBody := make([]Coordinate, 0, length)
+	for i := len(steps) - 1; i >= 0 && len(newBody) < length; i-- {
+		newBody = append(newBody, steps[i])
+	}
+	for i := 0; i < len(body) && len(newBody) < length; i++ {
+		newBody = append(newBody, body[i])
+	}
+	return newBody
+}
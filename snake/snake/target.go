@@ -0,0 +1,277 @@
+package snake
+
+import "sort"
+
+// Food targeting of the bot settings, how a bot picks its food when there is
+// more than one.
+const (
+	// TargetNearest goes for the food the bot reaches in the fewest moves.
+	TargetNearest = "nearest"
+	// TargetValue goes for the food with the most points per move.
+	TargetValue = "value"
+	// TargetRoute goes for the first food of the shortest route through
+	// several foods.
+	TargetRoute = "route"
+)
+
+// FoodTargets are the food targeting names in order.
+var FoodTargets = []string{TargetNearest, TargetValue, TargetRoute}
+
+// isFoodTarget tells if a name is a food targeting.
+func isFoodTarget(name string) bool {
+	for _, target := range FoodTargets {
+		if name == target {
+			return true
+		}
+	}
+	return false
+}
+
+// Route planning limits: the foods a route goes through and the nearest
+// foods it is made of.
+const (
+	routeLength     = 3
+	routeCandidates = 6
+)
+
+// foodTargets picks the food a bot goes for. It measures the moves the bot
+// and its opponents need to every food and prefers the food the bot gets to
+// before every opponent, of those it picks by its targeting. Snakes move in
+// their order, so on a tie the snake that moves first gets the food. Food
+// the bot can't reach is only a target if there is no other, then the
+// closest one as the crow flies.
+type foodTargets struct {
+	mode string
+	// team gives out the food of the bots of a team, nil without a team.
+	team *teamPlanner
+	own  distanceField
+	// before are the moves of the opponents that move before the bot, after
+	// the ones of those that move after it.
+	before distanceField
+	after  distanceField
+}
+
+// newFoodTargets creates the food targets of a targeting, nearest if the name
+// is empty or unknown.
+func (g *Game) newFoodTargets(mode string) *foodTargets {
+	if mode == "" {
+		mode = TargetNearest
+	}
+	if !isFoodTarget(mode) {
+		g.logger.Warn("unknown food targeting", "target", mode, "using", TargetNearest)
+		mode = TargetNearest
+	}
+	return &foodTargets{mode: mode}
+}
+
+// choose returns the food a snake goes for on the grid of its bot, false if
+// there is no food. Must be called on a bot view.
+func (t *foodTargets) choose(g *Game, snakeNumber int, gr *Grid) (Coordinate, bool) {
+	if t.team != nil {
+		if food, ok := t.team.foodOf(g, snakeNumber); ok {
+			return food, true
+		}
+	}
+	g.mu.Lock()
+	food := append([]Food{}, g.Food...)
+	g.mu.Unlock()
+	if len(food) <= 1 {
+		if len(food) == 0 {
+			return Coordinate{}, false
+		}
+		return food[0].Coordinates, true
+	}
+	return t.rank(g, snakeNumber, gr, nil)[0], true
+}
+
+// rank returns the food in the order a snake goes for it: the food it gets
+// to before every opponent in the order of its targeting, then the other
+// food it can reach, nearest first, and then the food it can't reach,
+// closest as the crow flies first. The snakes of its team are not opponents.
+// It measures the moves of the snake to every cell, see own. Must be called
+// on a bot view.
+func (t *foodTargets) rank(g *Game, snakeNumber int, gr *Grid, team []int) []Coordinate {
+	g.mu.Lock()
+	food := append([]Food{}, g.Food...)
+	g.mu.Unlock()
+
+	bodies := g.snakeBodies(snakeNumber)
+	head := bodies[snakeNumber][0]
+	for _, n := range team {
+		bodies[n] = nil
+	}
+	var before, after []Coordinate
+	for i, body := range bodies {
+		switch {
+		case body == nil || i == snakeNumber:
+		case i < snakeNumber:
+			before = append(before, body[0])
+		default:
+			after = append(after, body[0])
+		}
+	}
+	t.own.measure(gr, []Coordinate{head})
+	t.before.measure(gr, before)
+	t.after.measure(gr, after)
+
+	first := make([]Food, 0, len(food))
+	reachable := make([]Food, 0, len(food))
+	unreachable := make([]Coordinate, 0)
+	for _, f := range food {
+		own := t.own.at(gr, f.Coordinates)
+		if own < 0 {
+			unreachable = append(unreachable, f.Coordinates)
+			continue
+		}
+		before, after := t.before.at(gr, f.Coordinates), t.after.at(gr, f.Coordinates)
+		if (before < 0 || own < before) && (after < 0 || own <= after) {
+			first = append(first, f)
+		} else {
+			reachable = append(reachable, f)
+		}
+	}
+
+	var ranked []Coordinate
+	switch t.mode {
+	case TargetValue:
+		ranked = t.mostValue(gr, first)
+	case TargetRoute:
+		ranked = t.route(gr, first)
+	default:
+		ranked = t.nearest(gr, first)
+	}
+	ranked = append(ranked, t.nearest(gr, reachable)...)
+	sort.SliceStable(unreachable, func(a, b int) bool {
+		return manhattan(head, unreachable[a]) < manhattan(head, unreachable[b])
+	})
+	return append(ranked, unreachable...)
+}
+
+// nearest returns the food nearest first.
+func (t *foodTargets) nearest(gr *Grid, food []Food) []Coordinate {
+	cells := coordinatesOf(food)
+	sort.SliceStable(cells, func(a, b int) bool {
+		return t.own.at(gr, cells[a]) < t.own.at(gr, cells[b])
+	})
+	return cells
+}
+
+// mostValue returns the food with the most points per move first.
+func (t *foodTargets) mostValue(gr *Grid, food []Food) []Coordinate {
+	food = append([]Food{}, food...)
+	value := func(f Food) float64 {
+		return float64(f.Point) / float64(t.own.at(gr, f.Coordinates))
+	}
+	sort.SliceStable(food, func(a, b int) bool {
+		return value(food[a]) > value(food[b])
+	})
+	return coordinatesOf(food)
+}
+
+// route returns the food along the shortest route through the nearest foods
+// and then the food off the route, nearest first. A route costs the moves to
+// its first food and the distances between the others as the crow flies.
+func (t *foodTargets) route(gr *Grid, food []Food) []Coordinate {
+	all := t.nearest(gr, food)
+	cells := all
+	if len(cells) > routeCandidates {
+		cells = cells[:routeCandidates]
+	}
+	length := routeLength
+	if length > len(cells) {
+		length = len(cells)
+	}
+
+	var best []Coordinate
+	bestCost := -1
+	route := make([]Coordinate, 0, length)
+	used := make([]bool, len(cells))
+	var extend func(cost int)
+	extend = func(cost int) {
+		if bestCost >= 0 && cost >= bestCost {
+			return
+		}
+		if len(route) == length {
+			best, bestCost = append(best[:0], route...), cost
+			return
+		}
+		for i, c := range cells {
+			if used[i] {
+				continue
+			}
+			step := t.own.at(gr, c)
+			if len(route) > 0 {
+				step = manhattan(route[len(route)-1], c)
+			}
+			used[i] = true
+			route = append(route, c)
+			extend(cost + step)
+			route = route[:len(route)-1]
+			used[i] = false
+		}
+	}
+	extend(0)
+	ranked := append([]Coordinate{}, best...)
+	for _, c := range all {
+		onRoute := false
+		for _, r := range best {
+			onRoute = onRoute || r == c
+		}
+		if !onRoute {
+			ranked = append(ranked, c)
+		}
+	}
+	return ranked
+}
+
+// coordinatesOf returns the coordinates of the food.
+func coordinatesOf(food []Food) []Coordinate {
+	cells := make([]Coordinate, len(food))
+	for i, f := range food {
+		cells[i] = f.Coordinates
+	}
+	return cells
+}
+
+// distanceField holds the moves from the nearest of a set of cells to every
+// cell of a grid.
+type distanceField struct {
+	dist  []int
+	queue []int32
+}
+
+// measure fills the field with a breadth first search from the cells, through
+// the cells that are not blockers.
+func (f *distanceField) measure(gr *Grid, from []Coordinate) {
+	size := len(gr.Kinds)
+	if len(f.dist) != size {
+		f.dist = make([]int, size)
+	}
+	for i := range f.dist {
+		f.dist[i] = -1
+	}
+	f.queue = f.queue[:0]
+	for _, c := range from {
+		if gr.Inside(c) {
+			i := gr.Index(c)
+			f.dist[i] = 0
+			f.queue = append(f.queue, int32(i))
+		}
+	}
+	for k := 0; k < len(f.queue); k++ {
+		i := int(f.queue[k])
+		for dir := Up; dir <= Down; dir++ {
+			j, ok := neighborCell(gr, i, dir)
+			if !ok || f.dist[j] >= 0 || !gr.Walkable(j) {
+				continue
+			}
+			f.dist[j] = f.dist[i] + 1
+			f.queue = append(f.queue, int32(j))
+		}
+	}
+}
+
+// at returns the moves to a cell, -1 if it can't be reached.
+func (f *distanceField) at(gr *Grid, c Coordinate) int {
+	return f.dist[gr.Index(c)]
+}
@@ -0,0 +1,95 @@
+package snake
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFoodTargetsRank(t *testing.T) {
+	route := []string{
+		"##############",
+		"#*...A..*.*..#",
+		"#....a.......#",
+		"##############",
+	}
+	value := []string{
+		"##############",
+		"#*...A..*.$..#",
+		"#....a.......#",
+		"##############",
+	}
+	contested := []string{
+		"##########",
+		"#......*.#",
+		"#........#",
+		"#.*.Bbb..#",
+		"#..A.....#",
+		"#..a.....#",
+		"##########",
+	}
+	tests := []struct {
+		name  string
+		rows  []string
+		mode  string
+		snake int
+		team  []int
+		want  []Coordinate
+	}{
+		{"nearest", route, TargetNearest, 0, nil,
+			[]Coordinate{newCoordinate(8, 1), newCoordinate(1, 1), newCoordinate(10, 1)}},
+		{"route", route, TargetRoute, 0, nil,
+			[]Coordinate{newCoordinate(1, 1), newCoordinate(8, 1), newCoordinate(10, 1)}},
+		{"value", value, TargetValue, 0, nil,
+			[]Coordinate{newCoordinate(10, 1), newCoordinate(8, 1), newCoordinate(1, 1)}},
+		{"bonus food is nearest food", value, TargetNearest, 0, nil,
+			[]Coordinate{newCoordinate(8, 1), newCoordinate(1, 1), newCoordinate(10, 1)}},
+		{"contested", contested, TargetNearest, 1, nil,
+			[]Coordinate{newCoordinate(7, 1), newCoordinate(2, 3)}},
+		{"tie goes to the snake that moves first", contested, TargetNearest, 0, nil,
+			[]Coordinate{newCoordinate(2, 3), newCoordinate(7, 1)}},
+		{"teammates are no opponents", contested, TargetNearest, 1, []int{0},
+			[]Coordinate{newCoordinate(2, 3), newCoordinate(7, 1)}},
+		{"unreachable last", []string{
+			"#######",
+			"#*B...#",
+			"#21.*.#",
+			"#..A*.#",
+			"#..a..#",
+			"#######",
+		}, TargetNearest, 0, nil,
+			[]Coordinate{newCoordinate(4, 3), newCoordinate(4, 2), newCoordinate(1, 1)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := scenarioGame(t, tt.rows...)
+			view := g.botView(g.Snapshot())
+			gr := newBoardGrid(view.Board)
+			view.fillGrid(gr, view.Snakes[tt.snake])
+			targets := g.newFoodTargets(tt.mode)
+			if got := targets.rank(view, tt.snake, gr, tt.team); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rank = %v, want %v", got, tt.want)
+			}
+			if got, ok := targets.choose(view, tt.snake, gr); tt.team == nil && (!ok || got != tt.want[0]) {
+				t.Errorf("choose = %v %v, want %v", got, ok, tt.want[0])
+			}
+		})
+	}
+}
+
+// scenarioGame creates the game of a scenario board, every snake a player.
+func scenarioGame(t *testing.T, rows ...string) *Game {
+	t.Helper()
+	text := "board\n"
+	for _, row := range rows {
+		text += row + "\n"
+	}
+	sc, err := ParseScenario(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := sc.NewGame()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
@@ -0,0 +1,290 @@
+package snake
+
+import (
+	"sort"
+	"sync"
+)
+
+// teamPlanner plans the moves of the bots of a team together, one shared
+// planner per team. The first bot of the team that decides in a tick plans
+// for all of them, the others take their share of the plan from it.
+//
+// The food is spread over the team by the food targeting of the bots, which
+// doesn't count teammates as opponents. Every bot offers the food it would go
+// for first, and the closest pair of bot and food is matched until every bot
+// has its food, so no two bots go for the same food. Then the bots plan one
+// after the other with cooperative A*, a space-time search that keeps out of
+// the cells reserved along the paths of the bots that planned before.
+//
+// The members still decide with their own bots, see teamMember: the plan
+// only gives them their food and the ways of their teammates to keep out of.
+type teamPlanner struct {
+	mu       sync.Mutex
+	members  []int
+	grid     *Grid
+	search   *spaceTimeSearch
+	reserved *reservationTable
+	// view is the bot view the plans were made on, plans and food are the
+	// ones of every member with a path.
+	view  *Game
+	plans map[int][]Coordinate
+	food  map[int]Coordinate
+	// targets rank the food of every member, with the targeting of its bot.
+	targets map[int]*foodTargets
+}
+
+func newTeamPlanner(board *Board) *teamPlanner {
+	p := &teamPlanner{
+		grid:     newBoardGrid(board),
+		search:   newSpaceTimeSearch(),
+		reserved: newReservationTable(),
+		plans:    make(map[int][]Coordinate),
+		food:     make(map[int]Coordinate),
+		targets:  make(map[int]*foodTargets),
+	}
+	p.search.reserved = p.reserved
+	return p
+}
+
+// teamPlanner returns the planner of a team, it is created with the first bot
+// of the team.
+func (g *Game) teamPlanner(team string) *teamPlanner {
+	if g.teams == nil {
+		g.teams = make(map[string]*teamPlanner)
+	}
+	p, ok := g.teams[team]
+	if !ok {
+		p = newTeamPlanner(g.Board)
+		g.teams[team] = p
+	}
+	return p
+}
+
+// teamMember is a bot that can play in a team: it goes for the food the team
+// gave it and keeps out of the ways of its teammates, the rest it decides as
+// it would alone. Joining returns the food targets of the bot, the team gives
+// out the food by their targeting.
+type teamMember interface {
+	joinTeam(p *teamPlanner) *foodTargets
+}
+
+// join adds the bot of a snake to the team. It is false if the bot can't play
+// in a team, it then plays alone.
+func (p *teamPlanner) join(snakeNumber int, bot Bot) bool {
+	member, ok := bot.(teamMember)
+	if !ok {
+		return false
+	}
+	p.members = append(p.members, snakeNumber)
+	sort.Ints(p.members)
+	// the bots rank their food on their own grids at the same time
+	p.targets[snakeNumber] = &foodTargets{mode: member.joinTeam(p).mode}
+	return true
+}
+
+// teamwork is what the bot of a member takes from its team, nothing without a
+// team.
+type teamwork struct {
+	team *teamPlanner
+	// reserved are the cells of the ways of the teammates, for the
+	// space-time search of the bot.
+	reserved *reservationTable
+}
+
+// join ties the food targets and the space-time search of a bot to a team.
+func (w *teamwork) join(p *teamPlanner, targets *foodTargets, timed *spaceTimeSearch) {
+	w.team, w.reserved = p, newReservationTable()
+	targets.team = p
+	timed.reserved = w.reserved
+}
+
+// keepOut blocks the cells the teammates of a snake move to next on the grid
+// of its bot and reserves their ways. Must be called on a bot view.
+func (w *teamwork) keepOut(g *Game, snakeNumber int, gr *Grid) {
+	if w.team != nil {
+		w.team.keepOut(g, snakeNumber, gr, w.reserved)
+	}
+}
+
+// plan plans for the whole team if the view is new. Must hold p.mu.
+func (p *teamPlanner) plan(view *Game) {
+	if p.view != view {
+		p.view = view
+		p.planAll(view)
+	}
+}
+
+// foodOf returns the food the team gave a member, false if it has none.
+func (p *teamPlanner) foodOf(view *Game, snakeNumber int) (Coordinate, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.plan(view)
+	food, ok := p.food[snakeNumber]
+	return food, ok
+}
+
+// keepOut blocks the next cells of the planned paths of the teammates of a
+// member on a grid and reserves their paths in a table. Teammates without a
+// path reserve every cell next to their head.
+func (p *teamPlanner) keepOut(view *Game, snakeNumber int, gr *Grid, reserved *reservationTable) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.plan(view)
+	reserved.clear()
+	for _, n := range p.members {
+		if n == snakeNumber {
+			continue
+		}
+		path, ok := p.plans[n]
+		if !ok {
+			head := view.Snakes[n].SnakeParts[0].Coordinate
+			for dir := Up; dir <= Down; dir++ {
+				reserved.reserve(gr.Index(head.step(dir)), 1, 1)
+			}
+			continue
+		}
+		gr.Set(path[1], KindBlocker)
+		reservePath(gr, reserved, path, len(view.Snakes[n].SnakeParts)+1)
+	}
+}
+
+// planAll plans the paths of the members to their food, in the order of the
+// snakes.
+func (p *teamPlanner) planAll(view *Game) {
+	p.reserved.clear()
+	for n := range p.plans {
+		delete(p.plans, n)
+		delete(p.food, n)
+	}
+	targets := p.assignFood(view)
+	for _, n := range p.members {
+		snake := view.Snakes[n]
+		head := snake.SnakeParts[0].Coordinate
+		view.fillGrid(p.grid, snake)
+		var path []Coordinate
+		for _, food := range targets[n] {
+			if found, _, ok := p.search.Path(p.grid, head, food); ok && len(found) >= 2 {
+				path = append([]Coordinate{}, found...)
+				break
+			}
+		}
+		if path == nil {
+			// it decides alone, keep clear of every move it can make
+			for dir := Up; dir <= Down; dir++ {
+				p.reserved.reserve(p.grid.Index(head.step(dir)), 1, 1)
+			}
+			continue
+		}
+		view.logger.Debug("team plan", "snake", n+1, "food", path[len(path)-1], "steps", len(path)-1)
+		p.plans[n] = path
+		p.food[n] = path[len(path)-1]
+		reservePath(p.grid, p.reserved, path, len(snake.SnakeParts)+1)
+	}
+}
+
+// assignFood returns the food every member goes for, the one it was matched
+// with first and then the food nobody was matched with, in the order of its
+// targeting.
+func (p *teamPlanner) assignFood(view *Game) map[int][]Coordinate {
+	ranked := make(map[int][]Coordinate, len(p.members))
+	for _, n := range p.members {
+		view.fillGrid(p.grid, view.Snakes[n])
+		ranked[n] = p.targets[n].rank(view, n, p.grid, p.members)
+	}
+	// the moves of a member to a food, the ones it can't reach last
+	moves := func(n int, c Coordinate) int {
+		if d := p.targets[n].own.at(p.grid, c); d >= 0 {
+			return d
+		}
+		return dstarInf
+	}
+
+	matched := make(map[int]Coordinate, len(p.members))
+	taken := make(map[Coordinate]bool)
+	for len(matched) < len(p.members) {
+		best, bestFood, bestMoves := -1, Coordinate{}, 0
+		for _, n := range p.members {
+			if _, ok := matched[n]; ok {
+				continue
+			}
+			for _, c := range ranked[n] {
+				if taken[c] {
+					continue
+				}
+				if m := moves(n, c); best < 0 || m < bestMoves {
+					best, bestFood, bestMoves = n, c, m
+				}
+				break
+			}
+		}
+		if best < 0 {
+			break
+		}
+		matched[best] = bestFood
+		taken[bestFood] = true
+	}
+
+	targets := make(map[int][]Coordinate, len(p.members))
+	for _, n := range p.members {
+		if food, ok := matched[n]; ok {
+			targets[n] = append(targets[n], food)
+		}
+		for _, c := range ranked[n] {
+			if !taken[c] {
+				targets[n] = append(targets[n], c)
+			}
+		}
+	}
+	return targets
+}
+
+// reservePath reserves the cells of a path for the moves the body of a snake
+// of a length is on them. Where the snake goes after the path is unknown, so
+// the cells around its end are reserved for the move after.
+func reservePath(gr *Grid, reserved *reservationTable, path []Coordinate, length int) {
+	for t := 1; t < len(path); t++ {
+		reserved.reserve(gr.Index(path[t]), t, t+length)
+	}
+	end := path[len(path)-1]
+	for dir := Up; dir <= Down; dir++ {
+		reserved.reserve(gr.Index(end.step(dir)), len(path), len(path))
+	}
+}
+
+// reservationTable holds the cells the bots of a team will be on in the next
+// moves, counted from the next move as 1.
+type reservationTable struct {
+	cells map[int64]bool
+	// last is the last move with a reserved cell.
+	last int
+}
+
+func newReservationTable() *reservationTable {
+	return &reservationTable{cells: make(map[int64]bool)}
+}
+
+// reserve reserves a cell from one move to another.
+func (r *reservationTable) reserve(cell int, from, to int) {
+	for move := from; move <= to; move++ {
+		r.cells[reservationKey(cell, move)] = true
+	}
+	if to > r.last {
+		r.last = to
+	}
+}
+
+// taken tells if a cell is reserved in a move.
+func (r *reservationTable) taken(cell int, move int) bool {
+	return r.cells[reservationKey(cell, move)]
+}
+
+func (r *reservationTable) clear() {
+	for key := range r.cells {
+		delete(r.cells, key)
+	}
+	r.last = 0
+}
+
+func reservationKey(cell int, move int) int64 {
+	return int64(cell)<<32 | int64(move)
+}
@@ -0,0 +1,61 @@
+package snake
+
+import "testing"
+
+func TestReservationTable(t *testing.T) {
+	r := newReservationTable()
+	r.reserve(5, 2, 4)
+	r.reserve(7, 1, 1)
+	tests := []struct {
+		cell, move int
+		want       bool
+	}{
+		{5, 1, false},
+		{5, 2, true},
+		{5, 4, true},
+		{5, 5, false},
+		{7, 1, true},
+		{7, 2, false},
+		{6, 2, false},
+	}
+	for _, tt := range tests {
+		if got := r.taken(tt.cell, tt.move); got != tt.want {
+			t.Errorf("taken(%v, %v) = %v, want %v", tt.cell, tt.move, got, tt.want)
+		}
+	}
+	if r.last != 4 {
+		t.Errorf("last = %v, want 4", r.last)
+	}
+
+	r.clear()
+	if r.taken(5, 2) || r.last != 0 {
+		t.Errorf("cleared table has cell 5 in move 2 or last %v", r.last)
+	}
+}
+
+func TestReservePath(t *testing.T) {
+	gr := NewGrid(6, 6)
+	r := newReservationTable()
+	path := []Coordinate{newCoordinate(1, 1), newCoordinate(2, 1), newCoordinate(3, 1)}
+	reservePath(gr, r, path, 2)
+	tests := []struct {
+		c    Coordinate
+		move int
+		want bool
+	}{
+		{newCoordinate(1, 1), 1, false},
+		{newCoordinate(2, 1), 1, true},
+		{newCoordinate(2, 1), 3, true},
+		{newCoordinate(2, 1), 4, false},
+		{newCoordinate(3, 1), 2, true},
+		{newCoordinate(3, 1), 4, true},
+		{newCoordinate(4, 1), 3, true},
+		{newCoordinate(3, 2), 3, true},
+		{newCoordinate(4, 1), 2, false},
+	}
+	for _, tt := range tests {
+		if got := r.taken(gr.Index(tt.c), tt.move); got != tt.want {
+			t.Errorf("taken(%v, %v) = %v, want %v", tt.c, tt.move, got, tt.want)
+		}
+	}
+}
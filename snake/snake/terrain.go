@@ -0,0 +1,103 @@
+package snake
+
+import (
+	"math/rand"
+
+	"github.com/gdamore/tcell"
+)
+
+// mudSkip is the number of moves a snake sits out after it enters mud.
+const mudSkip = 1
+
+// defaultPatchSize is the number of cells of a terrain patch if the settings
+// don't tell.
+const defaultPatchSize = 6
+
+// terrainNames are the names of the terrain kinds a board can have.
+var terrainNames = map[int]string{
+	KindMud:   "mud",
+	KindIce:   "ice",
+	KindGrass: "grass",
+}
+
+// terrainColors are the background colors of the terrain kinds on the screen.
+var terrainColors = map[int]tcell.Color{
+	KindMud:   tcell.ColorSaddleBrown,
+	KindIce:   tcell.ColorLightCyan,
+	KindGrass: tcell.ColorDarkGreen,
+}
+
+// Terrain returns the tile kind of a cell, KindPlain if it has no terrain.
+func (b *Board) Terrain(c Coordinate) int {
+	if b.terrain == nil || c.x <= 0 || c.y <= 0 || c.x >= b.width || c.y >= b.height {
+		return KindPlain
+	}
+	return b.terrain[c.y*(b.width+1)+c.x]
+}
+
+// setTerrain puts a tile kind on a cell of the arena.
+func (b *Board) setTerrain(c Coordinate, kind int) {
+	if c.x <= 0 || c.y <= 0 || c.x >= b.width || c.y >= b.height {
+		return
+	}
+	if b.terrain == nil {
+		b.terrain = make([]int, (b.width+1)*(b.height+1))
+	}
+	b.terrain[c.y*(b.width+1)+c.x] = kind
+}
+
+// addTerrains grows the terrain patches of the settings on the board.
+func (b *Board) addTerrains(r *rand.Rand, setting TerrainSetting) {
+	size := setting.PatchSize
+	if size <= 0 {
+		size = defaultPatchSize
+	}
+	b.addTerrain(r, KindMud, setting.Mud, size)
+	b.addTerrain(r, KindIce, setting.Ice, size)
+	b.addTerrain(r, KindGrass, setting.Grass, size)
+}
+
+// addTerrain grows patches of a terrain kind, every patch is a random walk of
+// size steps from a random cell of the arena.
+func (b *Board) addTerrain(r *rand.Rand, kind int, patches int, size int) {
+	for i := 0; i < patches; i++ {
+		c := b.area[r.Intn(len(b.area))]
+		for j := 0; j < size; j++ {
+			b.setTerrain(c, kind)
+			next := c.step(r.Intn(4))
+			if next.x > 0 && next.y > 0 && next.x < b.width && next.y < b.height {
+				c = next
+			}
+		}
+	}
+}
+
+// fillTerrain draws the terrain of the board into a grid of the board.
+func (b *Board) fillTerrain(gr *Grid) {
+	if b.terrain == nil || len(b.terrain) != len(gr.Kinds) {
+		return
+	}
+	for i, kind := range b.terrain {
+		if kind != KindPlain {
+			gr.Kinds[i] = kind
+		}
+	}
+}
+
+// hidden tells if a snake is hidden from the bots of the other snakes, as its
+// head is in grass. Must hold g.mu.
+func (g *Game) hidden(s *Snake) bool {
+	return g.Board.Terrain(s.SnakeParts[0].Coordinate) == KindGrass
+}
+
+// Display the terrain of the board as background colors.
+func (g *Game) drawTerrain() {
+	if g.Board.terrain == nil {
+		return
+	}
+	for _, c := range g.Board.area {
+		if color, ok := terrainColors[g.Board.Terrain(c)]; ok {
+			g.Screen.SetContent(c.x, c.y, ' ', nil, tcell.StyleDefault.Background(color))
+		}
+	}
+}